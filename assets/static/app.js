@@ -3,15 +3,19 @@ class ClockManager {
         this.config = config;
         this.timeEl = document.getElementById('clock-time');
         this.dateEl = document.getElementById('clock-date');
+        // An empty timezone falls back to the browser's zone.
+        const timeZone = config.timezone || undefined;
         this.timeFormatter = new Intl.DateTimeFormat(config.locale, {
             hour: 'numeric',
             minute: '2-digit',
-            hour12: config.timeFormat === '12h'
+            hour12: config.timeFormat === '12h',
+            timeZone
         });
         this.dateFormatter = new Intl.DateTimeFormat(config.locale, {
             weekday: 'long',
             month: 'long',
-            day: 'numeric'
+            day: 'numeric',
+            timeZone
         });
         this.start();
     }
@@ -33,39 +37,75 @@ class SlideshowManager {
         this.config = config;
         this.container = document.getElementById('slideshow');
         this.slides = Array.from(this.container.querySelectorAll('.slide'));
-        this.currentIndex = 0;
-        this.photos = [];
+        this.captionEl = document.getElementById('slide-caption');
+        this.intervalMs = (parseInt(this.config.slideshow.interval) || 30) * 1000;
+        this.timer = null;
+        this.displayId = SlideshowManager.displayId();
         this.init();
     }
 
-    async init() {
+    // displayId names this display to the server, which keeps a place in
+    // the slideshow per display. It is kept across reloads where possible.
+    static displayId() {
         try {
-            const resp = await fetch('/api/photos');
-            const data = await resp.json();
-            this.photos = data.photos;
-            if (this.photos.length > 0) {
-                this.start();
+            let id = localStorage.getItem('kiosk-display');
+            if (!id) {
+                id = Math.random().toString(36).slice(2, 10);
+                localStorage.setItem('kiosk-display', id);
             }
+            return id;
         } catch (e) {
-            console.error("Failed to fetch photos:", e);
+            return '';
         }
     }
 
+    async init() {
+        const photo = await this.fetchNext();
+        if (!photo) {
+            // Photos may still be scanning on startup; try again later.
+            setTimeout(() => this.init(), this.intervalMs);
+            return;
+        }
+
+        await this.loadImage(this.slides[0], photo.path);
+        this.showCaption(photo);
+        this.start();
+    }
+
     start() {
-        this.loadImage(this.slides[0], this.photos[0]);
-        const intervalSec = parseInt(this.config.slideshow.interval) || 30;
-        setInterval(() => this.nextSlide(), intervalSec * 1000);
+        this.timer = setInterval(() => this.nextSlide(), this.intervalMs);
     }
 
-    nextSlide() {
-        const nextIndex = (this.currentIndex + 1) % this.photos.length;
+    skip() {
+        if (!this.timer) return;
+        clearInterval(this.timer);
+        this.nextSlide();
+        this.start();
+    }
+
+    async fetchNext() {
+        try {
+            const resp = await fetch('/api/photos/next?display=' + encodeURIComponent(this.displayId));
+            if (!resp.ok || resp.status === 204) return null;
+            return await resp.json();
+        } catch (e) {
+            console.error("Failed to fetch next photo:", e);
+            return null;
+        }
+    }
+
+    async nextSlide() {
+        const photo = await this.fetchNext();
+        if (!photo) return;
+
         const currentSlide = this.slides[0];
         const nextSlide = this.slides[1];
 
         // Load new image into next slide
-        this.loadImage(nextSlide, this.photos[nextIndex]).then(() => {
+        this.loadImage(nextSlide, photo.path).then(() => {
             nextSlide.classList.add('active');
             currentSlide.classList.remove('active');
+            this.showCaption(photo);
 
             // Wait for transition to finish, then clean up
             setTimeout(() => {
@@ -79,10 +119,14 @@ class SlideshowManager {
             }, 2000); // slightly longer than CSS transition
 
             this.slides.reverse();
-            this.currentIndex = nextIndex;
         });
     }
 
+    showCaption(photo) {
+        if (!this.captionEl) return;
+        this.captionEl.textContent = photo.caption || '';
+    }
+
     async loadImage(el, path) {
         try {
             const encodedPath = encodeURIComponent(path);
@@ -106,18 +150,30 @@ class SlideshowManager {
 }
 
 class DashboardClient {
-    constructor(config) {
+    constructor(config, slideshow) {
         this.config = config;
+        this.slideshow = slideshow;
         this.hash = "";
+        this.photoSkips = null;
+        const timeZone = config.timezone || undefined;
         this.dateFormatter = new Intl.DateTimeFormat(config.locale, {
-            month: 'short', day: 'numeric'
+            month: 'short', day: 'numeric', timeZone
         });
         this.timeFormatter = new Intl.DateTimeFormat(config.locale, {
-            hour: 'numeric', minute: '2-digit', hour12: config.timeFormat === '12h'
+            hour: 'numeric', minute: '2-digit', hour12: config.timeFormat === '12h', timeZone
         });
         this.relativeTimeFormatter = new Intl.DateTimeFormat(config.locale, {
-            hour: 'numeric', minute: '2-digit'
+            hour: 'numeric', minute: '2-digit', timeZone
         });
+        this.alerts = [];
+        this.alertsEl = document.getElementById('alerts');
+        if (this.alertsEl) {
+            this.alertsEl.addEventListener('click', (e) => {
+                const button = e.target.closest('.alert-dismiss');
+                if (button) this.dismissAlert(button.dataset.id);
+            });
+            setInterval(() => this.tickAlerts(), 1000);
+        }
         this.connect();
     }
 
@@ -153,12 +209,21 @@ class DashboardClient {
         if (resp.ok) {
             const data = await resp.json();
             this.hash = data.hash;
+            this.handleSkips(data.photo_skips);
             this.updateDOM(data.updates);
+            this.renderAlerts(data.alerts || []);
         } else {
             throw new Error(`Server returned ${resp.status}`);
         }
     }
 
+    handleSkips(skips) {
+        if (this.photoSkips !== null && skips !== this.photoSkips && this.slideshow) {
+            this.slideshow.skip();
+        }
+        this.photoSkips = skips;
+    }
+
     updateDOM(updates) {
         for (const [id, result] of Object.entries(updates)) {
             const section = document.getElementById(`section-${id}`);
@@ -188,6 +253,12 @@ class DashboardClient {
             case 'calendar':
                 this.renderCalendar(el, data);
                 break;
+            case 'tasks':
+                this.renderTasks(el, data);
+                break;
+            case 'birthdays':
+                this.renderBirthdays(el, data);
+                break;
         }
     }
 
@@ -239,55 +310,239 @@ class DashboardClient {
             return;
         }
 
+        // The highlighted item is the first one with a link; like the image
+        // renderer, its QR code follows it.
+        const highlighted = el.dataset.qrCode === 'true' ? data.items.find(item => item.link) : null;
+
         container.innerHTML = data.items.map(item => {
             // Summary is already cleaned by the server
             let summary = item.summary || '';
             const title = item.title || '';
+            const meta = [item.source, item.undated ? '' : this.formatRelativeTime(item.pub_date)]
+                .filter(Boolean).map(part => this.escapeHtml(part)).join(' · ');
+
+            const thumb = item.thumbnail
+                ? `<img class="news-thumb" src="/api/thumbnail?url=${encodeURIComponent(item.thumbnail)}" alt="" onerror="this.remove()">`
+                : '';
 
             return `
             <div class="news-item">
-                <div class="news-title">${this.escapeHtml(title)}</div>
-                ${summary ? `<div class="news-summary">${this.escapeHtml(summary)}</div>` : ''}
-                <div class="news-time">${this.formatRelativeTime(item.pub_date)}</div>
+                ${thumb}
+                <div class="news-body">
+                    <div class="news-title">${this.escapeHtml(title)}</div>
+                    ${summary ? `<div class="news-summary">${this.escapeHtml(summary)}</div>` : ''}
+                    ${meta ? `<div class="news-time">${meta}</div>` : ''}
+                </div>
             </div>
+            ${item === highlighted ? this.renderNewsQR(item) : ''}
         `}).join('');
     }
 
+    renderNewsQR(item) {
+        return `
+            <div class="news-qr">
+                <img class="qr-code" src="/api/qr?url=${encodeURIComponent(item.link)}" alt="" onerror="this.parentElement.remove()">
+                <span>Scan to read more</span>
+            </div>
+        `;
+    }
+
 
     renderCalendar(el, data) {
         const container = el.querySelector('[data-field="events"]');
+
+        // Failed sources are flagged; stale ones still show their last events.
+        const warnings = (data.sources || [])
+            .filter(source => source.state !== 'ok')
+            .map(source => `<div class="calendar-warning">${this.escapeHtml(source.name || 'Calendar')} unavailable</div>`)
+            .join('');
+
         if (!data.events || data.events.length === 0) {
-            container.innerHTML = '<div class="loading">No upcoming events</div>';
+            container.innerHTML = warnings + '<div class="loading">No upcoming events</div>';
             return;
         }
 
-        container.innerHTML = data.events.map(event => {
+        // With several calendars in the section, events also name their calendar.
+        const showCalendar = data.events.some(event => event.calendar !== data.events[0].calendar);
+
+        const renderEvent = event => {
             const startTime = new Date(event.start);
             const dateStr = this.dateFormatter.format(startTime);
             const timeStr = event.all_day ? 'All Day' : this.timeFormatter.format(startTime);
+            const color = /^#[0-9a-f]{3}([0-9a-f]{3})?$/i.test(event.color || '') ? event.color : '';
+            const details = [showCalendar ? event.calendar : '', event.location].filter(Boolean).join(' · ');
 
             return `
             <div class="event-item">
+                ${color ? `<div class="event-marker" style="background: ${color}"></div>` : ''}
                 <div class="event-time-badge">
                     <div class="event-date">${dateStr}</div>
                     <div class="event-time">${timeStr}</div>
                 </div>
                 <div class="event-details">
                     <div class="event-title">${this.escapeHtml(event.summary)}</div>
-                    ${event.location ? `<div class="event-location">${this.escapeHtml(event.location)}</div>` : ''}
+                    ${details ? `<div class="event-location">${this.escapeHtml(details)}</div>` : ''}
                 </div>
             </div>
-        `}).join('');
+        `;
+        };
+
+        // Without day groups, all events form one untitled day.
+        const days = data.days && data.days.length
+            ? data.days
+            : [{ label: '', index: 0, count: data.events.length }];
+
+        container.innerHTML = warnings + days.map(day => {
+            const events = data.events.slice(day.index, day.index + day.count);
+            const header = day.label ? `<div class="event-day">${this.escapeHtml(day.label)}</div>` : '';
+            return header + events.map(renderEvent).join('');
+        }).join('');
+    }
+
+    renderTasks(el, data) {
+        const container = el.querySelector('[data-field="tasks"]');
+
+        const warnings = (data.sources || [])
+            .filter(source => source.state !== 'ok')
+            .map(source => `<div class="calendar-warning">${this.escapeHtml(source.name || 'Tasks')} unavailable</div>`)
+            .join('');
+
+        if (!data.tasks || data.tasks.length === 0) {
+            container.innerHTML = warnings + '<div class="loading">No open tasks</div>';
+            return;
+        }
+
+        container.innerHTML = warnings + data.tasks.map(task => {
+            const color = /^#[0-9a-f]{3}([0-9a-f]{3})?$/i.test(task.color || '') ? task.color : '';
+            // Priorities 1-4 are high and 5 is medium (RFC 5545).
+            const priority = task.priority >= 1 && task.priority <= 4 ? '!!' : task.priority === 5 ? '!' : '';
+            let due = task.due_label || '';
+            if (task.due && !task.all_day) {
+                due = [due, this.timeFormatter.format(new Date(task.due))].filter(Boolean).join(' ');
+            }
+            const classes = ['task-item', task.overdue ? 'overdue' : '', task.completed ? 'completed' : '']
+                .filter(Boolean).join(' ');
+
+            return `
+            <div class="${classes}">
+                ${color ? `<div class="event-marker" style="background: ${color}"></div>` : ''}
+                <div class="task-check">${task.completed ? '&#9745;' : '&#9744;'}</div>
+                <div class="task-details">
+                    <div class="task-title">${priority ? `<span class="task-priority">${priority}</span> ` : ''}${this.escapeHtml(task.summary)}</div>
+                    ${due ? `<div class="task-due">${this.escapeHtml(due)}</div>` : ''}
+                </div>
+            </div>
+        `;
+        }).join('');
+    }
+
+    renderBirthdays(el, data) {
+        const container = el.querySelector('[data-field="birthdays"]');
+
+        const warnings = (data.sources || [])
+            .filter(source => source.state !== 'ok')
+            .map(source => `<div class="calendar-warning">${this.escapeHtml(source.name || 'Contacts')} unavailable</div>`)
+            .join('');
+
+        if (!data.birthdays || data.birthdays.length === 0) {
+            container.innerHTML = warnings + '<div class="loading">No upcoming birthdays</div>';
+            return;
+        }
+
+        container.innerHTML = warnings + data.birthdays.map(birthday => {
+            const details = [birthday.label, this.birthdayAge(birthday)].filter(Boolean).join(' · ');
+            return `
+            <div class="birthday-item${birthday.today ? ' today' : ''}">
+                <div class="birthday-name">${this.escapeHtml(birthday.name)}</div>
+                <div class="birthday-details">${this.escapeHtml(details)}</div>
+            </div>
+        `;
+        }).join('');
+    }
+
+    birthdayAge(birthday) {
+        if (birthday.kind === 'anniversary') {
+            return birthday.age ? `${birthday.age} years` : 'Anniversary';
+        }
+        return birthday.age ? `turns ${birthday.age}` : '';
+    }
+
+    renderAlerts(alerts) {
+        this.alerts = alerts;
+        if (!this.alertsEl) return;
+
+        this.alertsEl.innerHTML = alerts.map(alert => {
+            const details = [this.timeFormatter.format(new Date(alert.start)), alert.location, alert.calendar]
+                .filter(Boolean).map(part => this.escapeHtml(part)).join(' · ');
+            const color = alert.color ? ` style="border-left-color: ${this.escapeHtml(alert.color)}"` : '';
+            return `
+            <div class="alert-item" data-id="${this.escapeHtml(alert.id)}" data-start="${this.escapeHtml(alert.start)}"${color}>
+                <div class="alert-body">
+                    <div class="alert-title">${this.escapeHtml(alert.summary)}</div>
+                    <div class="alert-details">${details}</div>
+                </div>
+                <div class="alert-countdown"></div>
+                <button class="alert-dismiss" data-id="${this.escapeHtml(alert.id)}" aria-label="Dismiss">×</button>
+            </div>
+        `;
+        }).join('');
+        this.tickAlerts();
+    }
+
+    // tickAlerts updates the countdowns and drops the alerts whose event has
+    // started.
+    tickAlerts() {
+        const now = Date.now();
+        this.alertsEl.querySelectorAll('.alert-item').forEach(item => {
+            const left = new Date(item.dataset.start).getTime() - now;
+            if (left <= 0) {
+                item.remove();
+                return;
+            }
+            item.querySelector('.alert-countdown').textContent = this.countdownLabel(left);
+        });
+    }
+
+    // countdownLabel writes the time left with the words of the configured
+    // locale, the same way the rendered image does.
+    countdownLabel(ms) {
+        const words = config.countdown || { now: 'now', in: 'in %s', hour: 'h', minute: 'min' };
+        const minutes = Math.ceil(ms / 60000);
+        if (minutes <= 0) return words.now;
+        const hours = Math.floor(minutes / 60);
+        let left = `${minutes} ${words.minute}`;
+        if (hours > 0) {
+            left = minutes % 60 ? `${hours} ${words.hour} ${minutes % 60} ${words.minute}` : `${hours} ${words.hour}`;
+        }
+        return words.in.replace('%s', left);
+    }
+
+    async dismissAlert(id) {
+        const item = this.alertsEl.querySelector(`.alert-item[data-id="${CSS.escape(id)}"]`);
+        if (item) item.remove();
+        this.alerts = this.alerts.filter(alert => alert.id !== id);
+        try {
+            const resp = await fetch(`/api/alerts/${encodeURIComponent(id)}/dismiss`, { method: 'POST' });
+            if (!resp.ok && resp.status !== 404) {
+                throw new Error(`Server returned ${resp.status}`);
+            }
+        } catch (e) {
+            console.error("Dismiss failed:", e);
+        }
     }
 
     formatRelativeTime(dateStr) {
         const date = new Date(dateStr);
+        if (isNaN(date.getTime())) return '';
+
         const now = new Date();
         const diffMs = now - date;
-        const diffMins = Math.floor(diffMs / 60000);
+        const diffMins = Math.max(0, Math.floor(diffMs / 60000));
         const diffHours = Math.floor(diffMins / 60);
 
-        if (diffMins < 60) {
+        if (diffMins < 1) {
+            return 'just now';
+        } else if (diffMins < 60) {
             return `${diffMins}m ago`;
         } else if (diffHours < 24) {
             return `${diffHours}h ago`;
@@ -311,6 +566,6 @@ class DashboardClient {
 document.addEventListener('DOMContentLoaded', () => {
     const config = window.KIOSK_CONFIG;
     new ClockManager(config);
-    new SlideshowManager(config);
-    new DashboardClient(config);
+    const slideshow = new SlideshowManager(config);
+    new DashboardClient(config, slideshow);
 });
@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -25,6 +28,20 @@ type UIConfig struct {
 	Locale      string `yaml:"locale"`
 	TimeFormat  string `yaml:"time_format"`
 	Orientation string `yaml:"orientation"`
+	Timezone    string `yaml:"timezone"`
+}
+
+// Location returns the display time zone: the IANA zone named by Timezone,
+// or the host's local zone if it is unset or unknown.
+func (u UIConfig) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
 }
 
 type SlideshowConfig struct {
@@ -33,6 +50,26 @@ type SlideshowConfig struct {
 	Shuffle          bool           `yaml:"shuffle"`
 	Transition       string         `yaml:"transition"`
 	TargetResolution Resolution     `yaml:"target_resolution"`
+	RescanInterval   string         `yaml:"rescan_interval"`
+	Dedupe           *DedupeConfig  `yaml:"dedupe,omitempty"`
+	Upload           *UploadConfig  `yaml:"upload,omitempty"`
+}
+
+// UploadConfig enables the photo upload inbox. Dir must be inside one of
+// the local slideshow sources so uploads become part of the slideshow.
+type UploadConfig struct {
+	Token     string `yaml:"token"`
+	Dir       string `yaml:"dir"`
+	MaxSizeMB int    `yaml:"max_size_mb"`
+	StripEXIF bool   `yaml:"strip_exif"`
+	PlayNext  bool   `yaml:"play_next"`
+}
+
+// DedupeConfig enables perceptual-hash duplicate detection. Photos whose
+// hashes differ by at most MaxDistance bits are shown only once; 0 collapses
+// exact duplicates only, and a default applies when it is unset.
+type DedupeConfig struct {
+	MaxDistance *int `yaml:"max_distance,omitempty"`
 }
 
 type SourceConfig struct {
@@ -44,6 +81,8 @@ type SourceConfig struct {
 	AccessKey string `yaml:"access_key"`
 	SecretKey string `yaml:"secret_key"`
 	Endpoint  string `yaml:"endpoint"`
+	URL       string `yaml:"url"`
+	Limit     int    `yaml:"limit"`
 }
 
 type Resolution struct {
@@ -59,7 +98,11 @@ type Section struct {
 	Style     string           `yaml:"style"`
 	Weather   *WeatherConfig   `yaml:"weather,omitempty"`
 	RSS       *RSSConfig       `yaml:"rss,omitempty"`
+	QR        *QRConfig        `yaml:"qr,omitempty"`
 	Calendars []CalendarSource `yaml:"calendars,omitempty"`
+	Calendar  *CalendarConfig  `yaml:"calendar,omitempty"`
+	Tasks     *TasksConfig     `yaml:"tasks,omitempty"`
+	Birthdays *BirthdaysConfig `yaml:"birthdays,omitempty"`
 }
 
 type WeatherConfig struct {
@@ -70,18 +113,168 @@ type WeatherConfig struct {
 }
 
 type RSSConfig struct {
-	URL string `yaml:"url"`
+	URL           string        `yaml:"url"`
+	Feeds         []RSSFeed     `yaml:"feeds,omitempty"`
+	MaxItems      int           `yaml:"max_items"`
+	SummaryLength int           `yaml:"summary_length"`
+	Filter        *FilterConfig `yaml:"filter,omitempty"`
+	Thumbnails    *bool         `yaml:"thumbnails,omitempty"`
+	QRCode        bool          `yaml:"qr_code"`
 }
 
-type CalendarSource struct {
-	Type     string `yaml:"type"`
-	URL      string `yaml:"url"`
-	Name     string `yaml:"name"`
-	Username string `yaml:"username"`
+// ShowThumbnails reports whether item thumbnails are shown (the default).
+func (c *RSSConfig) ShowThumbnails() bool {
+	return c.Thumbnails == nil || *c.Thumbnails
+}
+
+// RSSFeed is one feed of a news section. Label is shown next to its items
+// and Weight (default 1) favors the feed when merging.
+type RSSFeed struct {
+	URL    string        `yaml:"url"`
+	Label  string        `yaml:"label"`
+	Weight float64       `yaml:"weight"`
+	Filter *FilterConfig `yaml:"filter,omitempty"`
+}
+
+// FilterConfig holds the rules news items must pass. Keywords match whole
+// words of the title or summary; regexes match either as-is. MaxAge drops
+// items older than the given duration.
+type FilterConfig struct {
+	Include      []string `yaml:"include,omitempty"`
+	Exclude      []string `yaml:"exclude,omitempty"`
+	IncludeRegex []string `yaml:"include_regex,omitempty"`
+	ExcludeRegex []string `yaml:"exclude_regex,omitempty"`
+	MaxAge       string   `yaml:"max_age"`
+}
+
+// QRConfig is the fixed payload of a "qr" section: either free text such as
+// a URL, or guest Wi-Fi credentials.
+type QRConfig struct {
+	Label string      `yaml:"label"`
+	Text  string      `yaml:"text"`
+	WiFi  *WiFiConfig `yaml:"wifi,omitempty"`
+}
+
+// WiFiConfig describes a network to join. Security is WPA (the default), WEP
+// or nopass; open networks default to nopass.
+type WiFiConfig struct {
+	SSID     string `yaml:"ssid"`
 	Password string `yaml:"password"`
-	Color    string `yaml:"color"`
+	Security string `yaml:"security"`
+	Hidden   bool   `yaml:"hidden"`
+}
+
+// Payload returns the text encoded in the QR code. Wi-Fi credentials use the
+// WIFI: URI scheme understood by phone cameras.
+func (q *QRConfig) Payload() string {
+	if q.WiFi == nil {
+		return q.Text
+	}
+
+	w := q.WiFi
+	security := w.Security
+	if security == "" {
+		security = "WPA"
+		if w.Password == "" {
+			security = "nopass"
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("WIFI:T:" + security + ";S:" + escapeWiFi(w.SSID) + ";")
+	if security != "nopass" {
+		b.WriteString("P:" + escapeWiFi(w.Password) + ";")
+	}
+	if w.Hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+var wifiEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, ":", `\:`, `"`, `\"`)
+
+func escapeWiFi(s string) string {
+	return wifiEscaper.Replace(s)
+}
+
+// CalendarConfig controls the agenda of a calendar section. DaysAhead
+// (default 7) counts the days after today that are shown; MaxEvents caps the
+// list (0 means no limit).
+type CalendarConfig struct {
+	DaysAhead  int  `yaml:"days_ahead"`
+	MaxEvents  int  `yaml:"max_events"`
+	HidePast   bool `yaml:"hide_past"`
+	HideAllDay bool `yaml:"hide_all_day"`
+	// SourceTimeout bounds each calendar fetch, e.g. "10s".
+	SourceTimeout string `yaml:"source_timeout"`
+	// ExportToken protects the section's feeds at /api/calendars/<id>.ics
+	// and .json; without it they are open.
+	ExportToken string `yaml:"export_token"`
 }
 
+// TasksConfig controls a tasks section. Its task lists are the section's
+// calendars. Completed tasks stay visible for CompletedGrace (default 0,
+// hidden at once), e.g. "24h"; MaxItems caps the list (0 means no limit).
+type TasksConfig struct {
+	MaxItems       int    `yaml:"max_items"`
+	CompletedGrace string `yaml:"completed_grace"`
+}
+
+// BirthdaysConfig controls a birthdays section. Its address books are the
+// section's calendars, of type carddav or vcf (a local file at url).
+// MaxItems (default 5) caps the list; DaysAhead limits it to the coming
+// days (0 means a full year).
+type BirthdaysConfig struct {
+	MaxItems  int `yaml:"max_items"`
+	DaysAhead int `yaml:"days_ahead"`
+}
+
+// CalendarSource is one calendar of a section. For ical sources URL may be
+// http(s), webcal, a file:// URL or an absolute path to an .ics file or a
+// directory of them; Username and Password or Token authenticate the
+// download and Headers are added to the request.
+type CalendarSource struct {
+	Type     string            `yaml:"type"`
+	URL      string            `yaml:"url"`
+	Name     string            `yaml:"name"`
+	Username string            `yaml:"username"`
+	Password string            `yaml:"password"`
+	Token    string            `yaml:"token"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	// Color marks the calendar's events on the display, as #rgb or #rrggbb.
+	Color string `yaml:"color"`
+	// Privacy is how CLASS:PRIVATE and CONFIDENTIAL events are shown: "show"
+	// (the default), "busy" or "hide".
+	Privacy  string         `yaml:"privacy"`
+	Rewrites []TitleRewrite `yaml:"rewrites,omitempty"`
+	// Email is the owner's address; invitations it declined are dropped.
+	// A username that is an address is used as well.
+	Email string `yaml:"email"`
+	// Calendars selects CalDAV calendars by display name or path. By default
+	// the calendar at URL, or the account's first event calendar, is used.
+	Calendars []string `yaml:"calendars,omitempty"`
+	// Reminders shows alerts on the display ahead of the calendar's events.
+	Reminders *ReminderConfig `yaml:"reminders,omitempty"`
+}
+
+// ReminderConfig sets when an event's alert appears: Before its start
+// (e.g. "15m") for every timed event, and at the event's own VALARMs when
+// Alarms is set. The earliest of them wins.
+type ReminderConfig struct {
+	Before string `yaml:"before"`
+	Alarms bool   `yaml:"alarms"`
+}
+
+// TitleRewrite replaces matches of a regex in event titles. Replace may use
+// $1 for submatches.
+type TitleRewrite struct {
+	Match   string `yaml:"match"`
+	Replace string `yaml:"replace"`
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -112,11 +305,142 @@ func (c *Config) Validate() error {
 		"bottom-right": true,
 	}
 
+	if c.UI.Timezone != "" {
+		if _, err := time.LoadLocation(c.UI.Timezone); err != nil {
+			return fmt.Errorf("invalid ui timezone '%s': %w", c.UI.Timezone, err)
+		}
+	}
+
+	for i, src := range c.Slideshow.Sources {
+		if src.Type == "feed" && src.URL == "" {
+			return fmt.Errorf("slideshow source %d: feed source requires a url", i)
+		}
+	}
+
+	if c.Slideshow.RescanInterval != "" {
+		if _, err := time.ParseDuration(c.Slideshow.RescanInterval); err != nil {
+			return fmt.Errorf("invalid slideshow rescan_interval '%s': %w", c.Slideshow.RescanInterval, err)
+		}
+	}
+
+	if u := c.Slideshow.Upload; u != nil {
+		if err := c.validateUpload(u); err != nil {
+			return err
+		}
+	}
+
+	if d := c.Slideshow.Dedupe; d != nil && d.MaxDistance != nil && (*d.MaxDistance < 0 || *d.MaxDistance > 64) {
+		return fmt.Errorf("invalid slideshow dedupe max_distance %d (must be between 0 and 64)", *d.MaxDistance)
+	}
+
 	for _, s := range c.Sections {
 		if s.Region != "" && !validRegions[s.Region] {
 			return fmt.Errorf("invalid region '%s' for section '%s'", s.Region, s.ID)
 		}
 
+		if s.RSS != nil {
+			if s.RSS.MaxItems < 0 {
+				return fmt.Errorf("invalid max_items %d for section '%s'", s.RSS.MaxItems, s.ID)
+			}
+			if s.RSS.SummaryLength < 0 {
+				return fmt.Errorf("invalid summary_length %d for section '%s'", s.RSS.SummaryLength, s.ID)
+			}
+			if err := s.RSS.Filter.validate(); err != nil {
+				return fmt.Errorf("invalid filter for section '%s': %w", s.ID, err)
+			}
+			for i, feed := range s.RSS.Feeds {
+				if feed.URL == "" {
+					return fmt.Errorf("feed %d of section '%s' requires a url", i, s.ID)
+				}
+				if feed.Weight < 0 {
+					return fmt.Errorf("invalid weight for feed %d of section '%s'", i, s.ID)
+				}
+				if err := feed.Filter.validate(); err != nil {
+					return fmt.Errorf("invalid filter for feed %d of section '%s': %w", i, s.ID, err)
+				}
+			}
+		}
+
+		for i, cal := range s.Calendars {
+			if cal.Color != "" && !hexColor.MatchString(cal.Color) {
+				return fmt.Errorf("invalid color '%s' for calendar %d of section '%s' (want #rgb or #rrggbb)", cal.Color, i, s.ID)
+			}
+			if len(cal.Calendars) > 0 && cal.Type != "caldav" {
+				return fmt.Errorf("calendars selection for calendar %d of section '%s' requires a caldav source", i, s.ID)
+			}
+			if (cal.Token != "" || len(cal.Headers) > 0) && cal.Type != "ical" {
+				return fmt.Errorf("token and headers for calendar %d of section '%s' require an ical source", i, s.ID)
+			}
+			if cal.Token != "" && cal.Username != "" {
+				return fmt.Errorf("calendar %d of section '%s' sets both token and username", i, s.ID)
+			}
+			switch cal.Privacy {
+			case "", "show", "busy", "hide":
+			default:
+				return fmt.Errorf("invalid privacy '%s' for calendar %d of section '%s' (want show, busy or hide)", cal.Privacy, i, s.ID)
+			}
+			for _, rw := range cal.Rewrites {
+				if _, err := regexp.Compile(rw.Match); err != nil {
+					return fmt.Errorf("invalid rewrite for calendar %d of section '%s': regex '%s': %w", i, s.ID, rw.Match, err)
+				}
+			}
+			if r := cal.Reminders; r != nil && r.Before != "" {
+				if d, err := time.ParseDuration(r.Before); err != nil || d <= 0 {
+					return fmt.Errorf("invalid reminders before '%s' for calendar %d of section '%s'", r.Before, i, s.ID)
+				}
+			}
+		}
+
+		if c := s.Calendar; c != nil {
+			if c.DaysAhead < 0 {
+				return fmt.Errorf("invalid days_ahead %d for section '%s'", c.DaysAhead, s.ID)
+			}
+			if c.MaxEvents < 0 {
+				return fmt.Errorf("invalid max_events %d for section '%s'", c.MaxEvents, s.ID)
+			}
+			if c.SourceTimeout != "" {
+				if d, err := time.ParseDuration(c.SourceTimeout); err != nil || d <= 0 {
+					return fmt.Errorf("invalid source_timeout '%s' for section '%s'", c.SourceTimeout, s.ID)
+				}
+			}
+		}
+
+		if t := s.Tasks; t != nil {
+			if t.MaxItems < 0 {
+				return fmt.Errorf("invalid max_items %d for section '%s'", t.MaxItems, s.ID)
+			}
+			if t.CompletedGrace != "" {
+				if d, err := time.ParseDuration(t.CompletedGrace); err != nil || d < 0 {
+					return fmt.Errorf("invalid completed_grace '%s' for section '%s'", t.CompletedGrace, s.ID)
+				}
+			}
+		}
+
+		if b := s.Birthdays; b != nil {
+			if b.MaxItems < 0 {
+				return fmt.Errorf("invalid max_items %d for section '%s'", b.MaxItems, s.ID)
+			}
+			if b.DaysAhead < 0 {
+				return fmt.Errorf("invalid days_ahead %d for section '%s'", b.DaysAhead, s.ID)
+			}
+		}
+		if s.Type == "birthdays" {
+			for i, cal := range s.Calendars {
+				if cal.Type != "carddav" && cal.Type != "vcf" {
+					return fmt.Errorf("invalid type '%s' for address book %d of section '%s' (want carddav or vcf)", cal.Type, i, s.ID)
+				}
+				if cal.URL == "" {
+					return fmt.Errorf("address book %d of section '%s' requires a url", i, s.ID)
+				}
+			}
+		}
+
+		if s.Type == "qr" {
+			if err := s.QR.validate(); err != nil {
+				return fmt.Errorf("invalid qr for section '%s': %w", s.ID, err)
+			}
+		}
+
 		if s.Interval != "" {
 			duration, err := time.ParseDuration(s.Interval)
 			if err != nil {
@@ -134,3 +458,78 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+func (c *Config) validateUpload(u *UploadConfig) error {
+	if u.Token == "" {
+		return fmt.Errorf("slideshow upload requires a token")
+	}
+	if u.Dir == "" {
+		return fmt.Errorf("slideshow upload requires a dir")
+	}
+	if u.MaxSizeMB < 0 {
+		return fmt.Errorf("invalid slideshow upload max_size_mb %d", u.MaxSizeMB)
+	}
+
+	dir, err := filepath.Abs(u.Dir)
+	if err != nil {
+		return fmt.Errorf("invalid slideshow upload dir '%s': %w", u.Dir, err)
+	}
+	for _, src := range c.Slideshow.Sources {
+		if src.Type != "local" {
+			continue
+		}
+		root, err := filepath.Abs(src.Path)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("slideshow upload dir '%s' is not inside a local source", u.Dir)
+}
+
+func (f *FilterConfig) validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, p := range append(append([]string{}, f.IncludeRegex...), f.ExcludeRegex...) {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("regex '%s': %w", p, err)
+		}
+	}
+	if f.MaxAge != "" {
+		if _, err := time.ParseDuration(f.MaxAge); err != nil {
+			return fmt.Errorf("max_age '%s': %w", f.MaxAge, err)
+		}
+	}
+	return nil
+}
+
+func (q *QRConfig) validate() error {
+	if q == nil {
+		return fmt.Errorf("missing qr settings")
+	}
+	if q.WiFi == nil {
+		if q.Text == "" {
+			return fmt.Errorf("text or wifi is required")
+		}
+		return nil
+	}
+	if q.Text != "" {
+		return fmt.Errorf("text and wifi are mutually exclusive")
+	}
+	if q.WiFi.SSID == "" {
+		return fmt.Errorf("wifi requires an ssid")
+	}
+	switch q.WiFi.Security {
+	case "", "WPA", "WEP":
+	case "nopass":
+		if q.WiFi.Password != "" {
+			return fmt.Errorf("wifi security nopass does not take a password")
+		}
+	default:
+		return fmt.Errorf("invalid wifi security '%s' (must be WPA, WEP or nopass)", q.WiFi.Security)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -162,6 +163,8 @@ slideshow:
   interval: "10s"
   shuffle: true
   transition: "fade"
+  dedupe:
+    max_distance: 0
   target_resolution:
     width: 1920
     height: 1080
@@ -197,6 +200,9 @@ slideshow:
 	if cfg.Slideshow.Transition != "fade" {
 		t.Errorf("Expected transition fade, got %s", cfg.Slideshow.Transition)
 	}
+	if d := cfg.Slideshow.Dedupe; d == nil || d.MaxDistance == nil || *d.MaxDistance != 0 {
+		t.Errorf("Expected an explicit dedupe max_distance of 0, got %+v", d)
+	}
 	if cfg.Slideshow.TargetResolution.Width != 1920 {
 		t.Errorf("Expected width 1920, got %d", cfg.Slideshow.TargetResolution.Width)
 	}
@@ -322,6 +328,370 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "DedupeDistanceOutOfRange",
+			config: Config{
+				Server:    ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{Dedupe: &DedupeConfig{MaxDistance: intPtr(65)}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "DedupeExactOnly",
+			config: Config{
+				Server:    ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{Dedupe: &DedupeConfig{MaxDistance: intPtr(0)}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "UploadInsideLocalSource",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{
+					Sources: []SourceConfig{{Type: "local", Path: "./photos"}},
+					Upload:  &UploadConfig{Token: "secret", Dir: "./photos/inbox"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "UploadOutsideLocalSource",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{
+					Sources: []SourceConfig{{Type: "local", Path: "./photos"}},
+					Upload:  &UploadConfig{Token: "secret", Dir: "./photos-inbox"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "UploadWithoutToken",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{
+					Sources: []SourceConfig{{Type: "local", Path: "./photos"}},
+					Upload:  &UploadConfig{Dir: "./photos"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "FeedSourceWithoutURL",
+			config: Config{
+				Server:    ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{Sources: []SourceConfig{{Type: "feed"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "FeedSourceOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Slideshow: SlideshowConfig{
+					Sources:        []SourceConfig{{Type: "feed", URL: "https://example.com/apod.rss", Limit: 5}},
+					RescanInterval: "1h",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "RSSFeedWithoutURL",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{
+					{ID: "news", Type: "rss", RSS: &RSSConfig{Feeds: []RSSFeed{{Label: "BBC"}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "RSSFeedsOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{
+					{ID: "news", Type: "rss", RSS: &RSSConfig{MaxItems: 8, Feeds: []RSSFeed{{URL: "https://example.com/rss", Label: "BBC", Weight: 2}}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "RSSFilterInvalidRegex",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{
+					{ID: "news", Type: "rss", RSS: &RSSConfig{URL: "https://example.com/rss", Filter: &FilterConfig{ExcludeRegex: []string{"("}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "RSSFeedFilterInvalidMaxAge",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{
+					{ID: "news", Type: "rss", RSS: &RSSConfig{Feeds: []RSSFeed{{URL: "https://example.com/rss", Filter: &FilterConfig{MaxAge: "2 days"}}}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "RSSFiltersOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{
+					{ID: "news", Type: "rss", RSS: &RSSConfig{
+						Filter: &FilterConfig{Exclude: []string{"election"}, MaxAge: "48h"},
+						Feeds:  []RSSFeed{{URL: "https://example.com/sport", Filter: &FilterConfig{Include: []string{"arsenal"}, IncludeRegex: []string{`(?i)gunners`}}}},
+					}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "CalendarInvalidDaysAhead",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendar: &CalendarConfig{DaysAhead: -1}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarInvalidMaxEvents",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendar: &CalendarConfig{MaxEvents: -5}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarInvalidColor",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics", Color: "red"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarColorsOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{
+					{Type: "ical", URL: "https://example.com/a.ics", Color: "#e33"},
+					{Type: "ical", URL: "https://example.com/b.ics", Color: "#4A90D9"},
+					{Type: "ical", URL: "https://example.com/c.ics"},
+					{Type: "caldav", URL: "https://cloud.example.com", Calendars: []string{"Family", "/remote.php/dav/calendars/jane/work/"}},
+				}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "CalendarInvalidSourceTimeout",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendar: &CalendarConfig{SourceTimeout: "soon"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarInvalidPrivacy",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics", Privacy: "secret"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarInvalidRewrite",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics", Rewrites: []TitleRewrite{{Match: "(", Replace: "x"}}}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarRulesOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{
+					{Type: "ical", URL: "https://example.com/a.ics", Privacy: "busy", Email: "jane@example.com", Rewrites: []TitleRewrite{{Match: `^\[EXT\]\s*`}}},
+				}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "CalendarSelectionNeedsCalDAV",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics", Calendars: []string{"Family"}}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarOptionsOK",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendar: &CalendarConfig{DaysAhead: 14, MaxEvents: 10, HidePast: true, SourceTimeout: "10s"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "TasksInvalidCompletedGrace",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "todo", Type: "tasks", Tasks: &TasksConfig{CompletedGrace: "a while"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TasksInvalidMaxItems",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "todo", Type: "tasks", Tasks: &TasksConfig{MaxItems: -1}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TasksOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "todo", Type: "tasks", Tasks: &TasksConfig{MaxItems: 8, CompletedGrace: "12h"},
+					Calendars: []CalendarSource{{Type: "caldav", URL: "https://cloud.example.com", Calendars: []string{"Chores"}}}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "CalendarTokenNeedsICal",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "caldav", URL: "https://cloud.example.com", Token: "abc"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarTokenAndUsername",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics", Token: "abc", Username: "jane"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarAuthenticatedAndLocalOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{
+					{Type: "ical", URL: "webcal://example.com/private.ics", Token: "abc", Headers: map[string]string{"X-Api-Key": "k"}},
+					{Type: "ical", URL: "https://example.com/export.ics", Username: "jane", Password: "secret"},
+					{Type: "ical", URL: "file:///home/pi/calendars/"},
+				}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "CalendarRemindersInvalidBefore",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics", Reminders: &ReminderConfig{Before: "-5m"}}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CalendarRemindersOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "cal", Type: "calendar", Calendars: []CalendarSource{
+					{Type: "ical", URL: "https://example.com/a.ics", Reminders: &ReminderConfig{Before: "15m", Alarms: true}},
+					{Type: "caldav", URL: "https://cloud.example.com", Reminders: &ReminderConfig{Alarms: true}},
+				}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "BirthdaysInvalidSourceType",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "bday", Type: "birthdays", Calendars: []CalendarSource{{Type: "ical", URL: "https://example.com/a.ics"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "BirthdaysMissingURL",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "bday", Type: "birthdays", Calendars: []CalendarSource{{Type: "vcf"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "BirthdaysInvalidDaysAhead",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "bday", Type: "birthdays", Birthdays: &BirthdaysConfig{DaysAhead: -1}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "BirthdaysOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "bday", Type: "birthdays", Birthdays: &BirthdaysConfig{MaxItems: 6, DaysAhead: 60},
+					Calendars: []CalendarSource{
+						{Type: "carddav", URL: "https://cloud.example.com", Username: "jane", Password: "secret"},
+						{Type: "vcf", URL: "/data/team.vcf"},
+					}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "InvalidTimezone",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				UI:     UIConfig{Timezone: "Mars/Olympus"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TimezoneOK",
+			config: Config{
+				Server: ServerConfig{Port: 8080},
+				UI:     UIConfig{Timezone: "Europe/Berlin"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "QRWithoutPayload",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "guest", Type: "qr", QR: &QRConfig{Label: "Wi-Fi"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "QRWithoutSettings",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "guest", Type: "qr"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "QRWiFiInvalidSecurity",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "guest", Type: "qr", QR: &QRConfig{WiFi: &WiFiConfig{SSID: "Guests", Security: "WPA3"}}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "QRWiFiOK",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Sections: []Section{{ID: "guest", Type: "qr", QR: &QRConfig{WiFi: &WiFiConfig{SSID: "Guests", Password: "secret"}}}},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -332,3 +702,38 @@ func TestValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestQRConfigPayload(t *testing.T) {
+	tests := []struct {
+		name string
+		qr   QRConfig
+		want string
+	}{
+		{"Text", QRConfig{Text: "https://example.com"}, "https://example.com"},
+		{"WPA", QRConfig{WiFi: &WiFiConfig{SSID: "Guests", Password: "secret"}}, "WIFI:T:WPA;S:Guests;P:secret;;"},
+		{"Open", QRConfig{WiFi: &WiFiConfig{SSID: "Cafe"}}, "WIFI:T:nopass;S:Cafe;;"},
+		{"Hidden", QRConfig{WiFi: &WiFiConfig{SSID: "Lab", Password: "pw", Security: "WEP", Hidden: true}}, "WIFI:T:WEP;S:Lab;P:pw;H:true;;"},
+		{"Escaped", QRConfig{WiFi: &WiFiConfig{SSID: `My;Net`, Password: `a:b,c\d"`}}, `WIFI:T:WPA;S:My\;Net;P:a\:b\,c\\d\";;`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.qr.Payload(); got != tt.want {
+				t.Errorf("Payload() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUIConfigLocation(t *testing.T) {
+	if loc := (UIConfig{}).Location(); loc != time.Local {
+		t.Errorf("Expected local zone by default, got %v", loc)
+	}
+	if loc := (UIConfig{Timezone: "Europe/Berlin"}).Location(); loc.String() != "Europe/Berlin" {
+		t.Errorf("Expected Europe/Berlin, got %v", loc)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,112 @@
+package images
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/disintegration/imaging"
+)
+
+// HashStore computes perceptual hashes for image files and persists them so
+// each photo is only decoded once.
+type HashStore struct {
+	path    string
+	entries map[string]hashEntry
+	dirty   bool
+	mu      sync.Mutex
+}
+
+type hashEntry struct {
+	Hash    string `json:"hash,omitempty"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Invalid bool   `json:"invalid,omitempty"`
+}
+
+// NewHashStore loads the hash file at path, starting empty if it does not exist.
+func NewHashStore(path string) (*HashStore, error) {
+	s := &HashStore{
+		path:    path,
+		entries: make(map[string]hashEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Hash returns the perceptual hash and file size of the image at path.
+// The hash is recomputed when the file changed since it was last stored.
+// ok is false when the file cannot be read or decoded.
+func (s *HashStore) Hash(path string) (hash uint64, size int64, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	s.mu.Lock()
+	entry, found := s.entries[path]
+	s.mu.Unlock()
+
+	if !found || entry.Size != info.Size() || entry.ModTime != info.ModTime().Unix() {
+		entry = hashEntry{Size: info.Size(), ModTime: info.ModTime().Unix()}
+		if img, err := imaging.Open(path); err == nil {
+			entry.Hash = strconv.FormatUint(DHash(img), 16)
+		} else {
+			entry.Invalid = true
+		}
+
+		s.mu.Lock()
+		s.entries[path] = entry
+		s.dirty = true
+		s.mu.Unlock()
+	}
+
+	if entry.Invalid {
+		return 0, entry.Size, false
+	}
+	hash, err = strconv.ParseUint(entry.Hash, 16, 64)
+	if err != nil {
+		return 0, entry.Size, false
+	}
+	return hash, entry.Size, true
+}
+
+// Save writes the store to disk if it changed.
+func (s *HashStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
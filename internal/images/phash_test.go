@@ -0,0 +1,69 @@
+package images
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/imaging"
+)
+
+func gradient(w, h int, invert bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if invert {
+				v = 255 - v
+			}
+			img.Set(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	original := gradient(200, 100, false)
+	resized := imaging.Resize(original, 90, 45, imaging.Linear)
+	inverted := gradient(200, 100, true)
+
+	if d := HammingDistance(DHash(original), DHash(resized)); d > 4 {
+		t.Errorf("Expected resized copy to be near-identical, distance %d", d)
+	}
+	if d := HammingDistance(DHash(original), DHash(inverted)); d < 32 {
+		t.Errorf("Expected inverted image to differ, distance %d", d)
+	}
+}
+
+func TestHashStore(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "photo.png")
+	if err := imaging.Save(gradient(40, 20, false), photo); err != nil {
+		t.Fatal(err)
+	}
+	storePath := filepath.Join(dir, "phash.json")
+
+	store, err := NewHashStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _, ok := store.Hash(photo)
+	if !ok {
+		t.Fatal("Expected hash for valid image")
+	}
+	if _, _, ok := store.Hash(filepath.Join(dir, "missing.png")); ok {
+		t.Error("Expected no hash for missing file")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewHashStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, ok := reloaded.Hash(photo); !ok || got != hash {
+		t.Errorf("Expected persisted hash %x, got %x", hash, got)
+	}
+}
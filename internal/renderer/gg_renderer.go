@@ -6,14 +6,20 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"bros_kiosk/internal/config"
 	"bros_kiosk/pkg/fetcher"
+	"bros_kiosk/pkg/textutil"
 
+	"github.com/disintegration/imaging"
 	"github.com/fogleman/gg"
 	"github.com/golang/freetype/truetype"
 	"github.com/goodsign/monday"
+	"github.com/skip2/go-qrcode"
 	"golang.org/x/image/font"
 )
 
@@ -58,6 +64,7 @@ func (r *GGRenderer) Render(ctx context.Context, opts RenderOptions, data Dashbo
 	dc := gg.NewContext(opts.Width, opts.Height)
 
 	r.drawBackground(dc, opts, data)
+	r.drawCaption(dc, opts, data)
 
 	clockHeight := r.drawClock(dc, opts, data)
 
@@ -86,10 +93,7 @@ func (r *GGRenderer) Render(ctx context.Context, opts RenderOptions, data Dashbo
 		"bottom-right": float64(opts.Width) - padding - colWidth,
 	}
 
-	var locale monday.Locale = monday.LocaleEnUS
-	if data.Locale != "" {
-		locale = monday.Locale(data.Locale)
-	}
+	locale := textutil.DateLocale(data.Locale)
 
 	for _, sec := range cfg.Sections {
 		region := sec.Region
@@ -122,13 +126,30 @@ func (r *GGRenderer) Render(ctx context.Context, opts RenderOptions, data Dashbo
 		case "rss":
 			if hasData {
 				if rd, ok := secData.(*fetcher.RSSData); ok {
-					heightDrawn = r.drawRSS(dc, opts, x, y, colWidth, rd, locale)
+					showQR := sec.RSS != nil && sec.RSS.QRCode
+					heightDrawn = r.drawRSS(dc, opts, x, y, colWidth, rd, data.Thumbnails, showQR, locale)
 				}
 			}
+		case "qr":
+			if sec.QR != nil {
+				heightDrawn = r.drawQRSection(dc, opts, x, y, colWidth, sec.QR)
+			}
 		case "calendar":
 			if hasData {
 				if cd, ok := secData.(*fetcher.CalendarData); ok {
-					heightDrawn = r.drawCalendar(dc, opts, x, y, colWidth, cd, locale)
+					heightDrawn = r.drawCalendar(dc, opts, x, y, colWidth, cd, data.Location, locale)
+				}
+			}
+		case "tasks":
+			if hasData {
+				if td, ok := secData.(*fetcher.TaskData); ok {
+					heightDrawn = r.drawTasks(dc, opts, x, y, colWidth, td, data.Location)
+				}
+			}
+		case "birthdays":
+			if hasData {
+				if bd, ok := secData.(*fetcher.BirthdayData); ok {
+					heightDrawn = r.drawBirthdays(dc, opts, x, y, colWidth, bd)
 				}
 			}
 		}
@@ -138,6 +159,8 @@ func (r *GGRenderer) Render(ctx context.Context, opts RenderOptions, data Dashbo
 		}
 	}
 
+	r.drawAlerts(dc, opts, data)
+
 	return dc.Image(), nil
 }
 
@@ -183,6 +206,25 @@ func (r *GGRenderer) drawBackground(dc *gg.Context, opts RenderOptions, data Das
 	}
 }
 
+func (r *GGRenderer) drawCaption(dc *gg.Context, opts RenderOptions, data DashboardData) {
+	if data.Background == nil || data.BackgroundCaption == "" {
+		return
+	}
+
+	captionSize := float64(opts.Height) * 0.016
+	padding := float64(opts.Width) * 0.025
+
+	dc.SetFontFace(r.fontFace(captionSize, true))
+	dc.SetRGBA(1, 1, 1, 0.6)
+
+	caption := data.BackgroundCaption
+	lines := dc.WordWrap(caption, float64(opts.Width)*0.5)
+	if len(lines) > 0 {
+		caption = lines[0]
+	}
+	dc.DrawStringAnchored(caption, float64(opts.Width)-padding, float64(opts.Height)-padding, 1.0, 0.0)
+}
+
 func (r *GGRenderer) drawClock(dc *gg.Context, opts RenderOptions, data DashboardData) float64 {
 	centerX := float64(opts.Width) / 2
 	clockY := float64(opts.Height) * 0.13
@@ -210,10 +252,7 @@ func (r *GGRenderer) drawClock(dc *gg.Context, opts RenderOptions, data Dashboar
 	dc.SetFontFace(r.fontFace(dateFontSize, true))
 	dc.SetRGBA(1, 1, 1, 0.7)
 
-	var locale monday.Locale = monday.LocaleEnUS
-	if data.Locale != "" {
-		locale = monday.Locale(data.Locale)
-	}
+	locale := textutil.DateLocale(data.Locale)
 	dateStr := monday.Format(t, "Monday, January 2", locale)
 
 	dc.DrawStringAnchored(dateStr, centerX, clockY+timeFontSize*0.7, 0.5, 0.5)
@@ -261,7 +300,7 @@ func (r *GGRenderer) drawWeather(dc *gg.Context, opts RenderOptions, x, y, width
 	return (curY - y) + condFontSize
 }
 
-func (r *GGRenderer) drawRSS(dc *gg.Context, opts RenderOptions, x, y, width float64, data *fetcher.RSSData, locale monday.Locale) float64 {
+func (r *GGRenderer) drawRSS(dc *gg.Context, opts RenderOptions, x, y, width float64, data *fetcher.RSSData, thumbs map[string]image.Image, showQR bool, locale monday.Locale) float64 {
 	if len(data.Items) == 0 {
 		return 0
 	}
@@ -271,40 +310,51 @@ func (r *GGRenderer) drawRSS(dc *gg.Context, opts RenderOptions, x, y, width flo
 	titleSize := float64(opts.Height) * 0.020
 	summarySize := float64(opts.Height) * 0.016
 	timeSize := float64(opts.Height) * 0.013
+	thumbSize := titleSize * 3.5
+	thumbGap := titleSize * 0.6
+	qrSize := titleSize * 6
 
 	dc.SetFontFace(r.fontFace(headerSize, false))
 	dc.SetRGBA(1, 1, 1, 0.45)
 	dc.DrawString("NEWS", x, y+headerSize)
 	y += headerSize * 3
 
-	maxItems := 5
-	if len(data.Items) < maxItems {
-		maxItems = len(data.Items)
-	}
+	for _, item := range data.Items {
+		if y+titleSize > float64(opts.Height) {
+			break
+		}
 
-	for i := 0; i < maxItems; i++ {
-		item := data.Items[i]
+		textX, textWidth := x, width
+		thumbBottom := y
+		if thumb := thumbs[item.Thumbnail]; thumb != nil {
+			top := y - titleSize
+			size := int(thumbSize)
+			dc.DrawImage(imaging.Fill(thumb, size, size, imaging.Center, imaging.Linear), int(x), int(top))
+			textX += thumbSize + thumbGap
+			textWidth -= thumbSize + thumbGap
+			thumbBottom = top + thumbSize
+		}
 
 		dc.SetFontFace(r.fontFace(titleSize, false))
 		dc.SetColor(color.White)
-		lines := dc.WordWrap(item.Title, width)
+		lines := dc.WordWrap(item.Title, textWidth)
 		if len(lines) > 2 {
 			lines = lines[:2]
 		}
 		for _, line := range lines {
-			dc.DrawString(line, x, y)
+			dc.DrawString(line, textX, y)
 			y += titleSize * 1.3
 		}
 
 		if item.Summary != "" {
 			dc.SetFontFace(r.fontFace(summarySize, true))
 			dc.SetRGBA(1, 1, 1, 0.65)
-			summaryLines := dc.WordWrap(item.Summary, width)
+			summaryLines := dc.WordWrap(item.Summary, textWidth)
 			if len(summaryLines) > 2 {
 				summaryLines = summaryLines[:2]
 			}
 			for _, line := range summaryLines {
-				dc.DrawString(line, x, y)
+				dc.DrawString(line, textX, y)
 				y += summarySize * 1.25
 			}
 		}
@@ -312,10 +362,35 @@ func (r *GGRenderer) drawRSS(dc *gg.Context, opts RenderOptions, x, y, width flo
 		dc.SetFontFace(r.fontFace(timeSize, true))
 		dc.SetRGBA(1, 1, 1, 0.45)
 
-		pubDate, _ := time.Parse(time.RFC1123Z, item.PubDate)
-		timeAgo := formatRelativeTime(pubDate)
-		dc.DrawString(timeAgo, x, y)
+		meta := ""
+		if !item.Undated {
+			meta = formatRelativeTime(item.PubDate)
+		}
+		if item.Source != "" {
+			if meta != "" {
+				meta = item.Source + " · " + meta
+			} else {
+				meta = item.Source
+			}
+		}
+		dc.DrawString(meta, textX, y)
 		y += timeSize * 1.5
+		// Keep the next item clear of this item's thumbnail.
+		if y < thumbBottom+thumbGap {
+			y = thumbBottom + thumbGap
+		}
+
+		// The highlighted item is the first one with a link; its QR code
+		// lets viewers open the full story on their phone.
+		if showQR && item.Link != "" {
+			showQR = false
+			if r.drawQRCode(dc, item.Link, textX, y, qrSize) {
+				dc.SetFontFace(r.fontFace(timeSize, true))
+				dc.SetRGBA(1, 1, 1, 0.45)
+				dc.DrawStringAnchored("Scan to read more", textX+qrSize+thumbGap, y+qrSize/2, 0, 0.5)
+				y += qrSize + thumbGap
+			}
+		}
 
 		y += titleSize * 1.0
 	}
@@ -323,8 +398,69 @@ func (r *GGRenderer) drawRSS(dc *gg.Context, opts RenderOptions, x, y, width flo
 	return y - startY
 }
 
-func (r *GGRenderer) drawCalendar(dc *gg.Context, opts RenderOptions, x, y, width float64, data *fetcher.CalendarData, locale monday.Locale) float64 {
-	if len(data.Events) == 0 {
+func (r *GGRenderer) drawQRSection(dc *gg.Context, opts RenderOptions, x, y, width float64, cfg *config.QRConfig) float64 {
+	startY := y
+	headerSize := float64(opts.Height) * 0.014
+	size := math.Min(width*0.6, float64(opts.Height)*0.25)
+
+	if cfg.Label != "" {
+		dc.SetFontFace(r.fontFace(headerSize, false))
+		dc.SetRGBA(1, 1, 1, 0.45)
+		dc.DrawStringAnchored(strings.ToUpper(cfg.Label), x+width/2, y+headerSize, 0.5, 0)
+		y += headerSize * 3
+	}
+
+	if !r.drawQRCode(dc, cfg.Payload(), x+(width-size)/2, y, size) {
+		return 0
+	}
+	return y + size - startY
+}
+
+// drawQRCode draws payload as a QR code on a white tile of the given size,
+// keeping a quiet zone around it. Modules are snapped to whole pixels so the
+// code stays scannable. It reports false if the payload cannot be encoded.
+func (r *GGRenderer) drawQRCode(dc *gg.Context, payload string, x, y, size float64) bool {
+	q, err := qrcode.New(payload, qrcode.Medium)
+	if err != nil {
+		return false
+	}
+	q.DisableBorder = true
+	bitmap := q.Bitmap()
+
+	quiet := size * 0.06
+	module := math.Max(1, math.Floor((size-2*quiet)/float64(len(bitmap))))
+	offset := (size - module*float64(len(bitmap))) / 2
+
+	dc.SetColor(color.White)
+	dc.DrawRoundedRectangle(x, y, size, size, 4)
+	dc.Fill()
+
+	originX := math.Round(x + offset)
+	originY := math.Round(y + offset)
+	dc.SetColor(color.Black)
+	for row, modules := range bitmap {
+		for col, dark := range modules {
+			if dark {
+				dc.DrawRectangle(originX+float64(col)*module, originY+float64(row)*module, module, module)
+			}
+		}
+	}
+	dc.Fill()
+	return true
+}
+
+func (r *GGRenderer) drawCalendar(dc *gg.Context, opts RenderOptions, x, y, width float64, data *fetcher.CalendarData, loc *time.Location, locale monday.Locale) float64 {
+	var unavailable []string
+	for _, source := range data.Sources {
+		if source.State != fetcher.SourceOK {
+			name := source.Name
+			if name == "" {
+				name = "Calendar"
+			}
+			unavailable = append(unavailable, name+" unavailable")
+		}
+	}
+	if len(data.Events) == 0 && len(unavailable) == 0 {
 		return 0
 	}
 
@@ -340,6 +476,8 @@ func (r *GGRenderer) drawCalendar(dc *gg.Context, opts RenderOptions, x, y, widt
 	badgePadding := float64(opts.Width) * 0.012
 	padding := float64(opts.Width) * 0.025
 	cornerRadius := 4.0
+	markerWidth := float64(opts.Width) * 0.003
+	markerGap := badgePadding * 0.5
 
 	dc.SetFontFace(r.fontFace(headerSize, false))
 	dc.SetRGBA(1, 1, 1, 0.45)
@@ -348,75 +486,445 @@ func (r *GGRenderer) drawCalendar(dc *gg.Context, opts RenderOptions, x, y, widt
 	dc.DrawString("CALENDAR", x+width-headerWidth, y+headerSize)
 	y += headerSize * 3
 
-	maxItems := 5
-	if len(data.Events) < maxItems {
-		maxItems = len(data.Events)
+	// Failed sources are flagged; stale ones still show their last events.
+	if len(unavailable) > 0 {
+		dc.SetFontFace(r.fontFace(locationSize, true))
+		dc.SetRGBA(0.88, 0.72, 0.29, 1)
+		for _, line := range unavailable {
+			lineWidth, _ := dc.MeasureString(line)
+			dc.DrawString(line, x+width-lineWidth, y+locationSize)
+			y += locationSize * 1.6
+		}
+		y += locationSize * 0.6
+	}
+
+	// With several calendars in the section, events also name their calendar.
+	showCalendar := false
+	for _, event := range data.Events {
+		if event.Calendar != data.Events[0].Calendar {
+			showCalendar = true
+			break
+		}
+	}
+
+	// Without day groups (older data), all events form one untitled day.
+	days := data.Days
+	if len(days) == 0 {
+		days = []fetcher.CalendarDay{{Index: 0, Count: len(data.Events)}}
 	}
 
-	for i := 0; i < maxItems; i++ {
-		event := data.Events[i]
+	for _, day := range days {
+		if day.Index < 0 || day.Index+day.Count > len(data.Events) {
+			continue
+		}
+		if y+headerSize*2.5+badgeHeight > float64(opts.Height) {
+			break
+		}
+
+		if day.Label != "" {
+			dc.SetFontFace(r.fontFace(headerSize, false))
+			dc.SetRGBA(1, 1, 1, 0.7)
+			label := strings.ToUpper(day.Label)
+			labelWidth, _ := dc.MeasureString(label)
+			dc.DrawString(label, x+width-labelWidth, y+headerSize)
+			y += headerSize * 2.5
+		}
 
-		badgeY := y
+		for _, event := range data.Events[day.Index : day.Index+day.Count] {
+			if y+badgeHeight > float64(opts.Height) {
+				break
+			}
+			start := event.Start
+			if loc != nil {
+				start = start.In(loc)
+			}
 
-		maxTitleWidth := width * 0.6
-		totalWidth := badgeWidth + badgePadding + maxTitleWidth
+			badgeY := y
 
-		groupX := x + width - totalWidth
+			maxTitleWidth := width * 0.6
+			totalWidth := badgeWidth + badgePadding + maxTitleWidth
 
-		badgeX := groupX
-		textXLocal := badgeX + badgeWidth + badgePadding
+			groupX := x + width - totalWidth
 
-		dc.SetRGBA(1, 1, 1, 0.15)
-		dc.DrawRoundedRectangle(badgeX, badgeY, badgeWidth, badgeHeight, cornerRadius)
-		dc.Fill()
+			badgeX := groupX
+			textXLocal := badgeX + badgeWidth + badgePadding
 
-		dc.SetFontFace(r.fontFace(dateSize, false))
-		dc.SetColor(color.White)
+			dc.SetRGBA(1, 1, 1, 0.15)
+			dc.DrawRoundedRectangle(badgeX, badgeY, badgeWidth, badgeHeight, cornerRadius)
+			dc.Fill()
 
-		dateStr := monday.Format(event.Start, "Jan 2", locale)
-		dc.DrawStringAnchored(dateStr, badgeX+badgeWidth/2, badgeY+badgeHeight*0.45, 0.5, 0.5)
+			if c, ok := parseHexColor(event.Color); ok {
+				dc.SetColor(c)
+				dc.DrawRoundedRectangle(badgeX-markerGap-markerWidth, badgeY, markerWidth, badgeHeight, markerWidth/2)
+				dc.Fill()
+			}
 
-		dc.SetFontFace(r.fontFace(timeSize, true))
-		dc.SetRGBA(1, 1, 1, 0.7)
-		var timeStr string
-		if event.AllDay {
-			timeStr = "All Day"
-		} else {
-			timeStr = event.Start.Format("15:04")
+			dc.SetFontFace(r.fontFace(dateSize, false))
+			dc.SetColor(color.White)
+
+			dateStr := monday.Format(start, "Jan 2", locale)
+			dc.DrawStringAnchored(dateStr, badgeX+badgeWidth/2, badgeY+badgeHeight*0.45, 0.5, 0.5)
+
+			dc.SetFontFace(r.fontFace(timeSize, true))
+			dc.SetRGBA(1, 1, 1, 0.7)
+			var timeStr string
+			if event.AllDay {
+				timeStr = "All Day"
+			} else {
+				timeStr = start.Format("15:04")
+			}
+			dc.DrawStringAnchored(timeStr, badgeX+badgeWidth/2, badgeY+badgeHeight*0.75, 0.5, 0.5)
+
+			dc.SetFontFace(r.fontFace(titleSize, false))
+			dc.SetColor(color.White)
+
+			title := event.Summary
+			lines := dc.WordWrap(title, maxTitleWidth)
+			if len(lines) > 2 {
+				lines = lines[:2]
+			}
+
+			currentTextY := badgeY + badgeHeight*0.45 + titleSize*0.35
+			for _, line := range lines {
+				dc.DrawString(line, textXLocal, currentTextY)
+				currentTextY += titleSize * 1.2
+			}
+
+			details := event.Location
+			if showCalendar && event.Calendar != "" {
+				details = event.Calendar
+				if event.Location != "" {
+					details += " · " + event.Location
+				}
+			}
+			if details != "" {
+				dc.SetFontFace(r.fontFace(locationSize, true))
+				dc.SetRGBA(1, 1, 1, 0.6)
+				locY := badgeY + badgeHeight*0.75 + locationSize*0.35
+				if currentTextY > locY-locationSize {
+					locY = currentTextY + locationSize*0.2
+				}
+				dc.DrawString(details, textXLocal, locY)
+			}
+
+			y += badgeHeight + padding*0.5
 		}
-		dc.DrawStringAnchored(timeStr, badgeX+badgeWidth/2, badgeY+badgeHeight*0.75, 0.5, 0.5)
+	}
 
-		dc.SetFontFace(r.fontFace(titleSize, false))
-		dc.SetColor(color.White)
+	return y - startY
+}
+
+func (r *GGRenderer) drawTasks(dc *gg.Context, opts RenderOptions, x, y, width float64, data *fetcher.TaskData, loc *time.Location) float64 {
+	var unavailable []string
+	for _, source := range data.Sources {
+		if source.State != fetcher.SourceOK {
+			name := source.Name
+			if name == "" {
+				name = "Tasks"
+			}
+			unavailable = append(unavailable, name+" unavailable")
+		}
+	}
+	if len(data.Tasks) == 0 && len(unavailable) == 0 {
+		return 0
+	}
 
-		title := event.Summary
+	startY := y
+	headerSize := float64(opts.Height) * 0.012
+	titleSize := float64(opts.Height) * 0.02
+	dueSize := float64(opts.Height) * 0.015
+	boxSize := titleSize * 0.8
+	boxGap := float64(opts.Width) * 0.008
+	markerWidth := float64(opts.Width) * 0.003
+	markerGap := boxGap * 0.5
+	padding := float64(opts.Width) * 0.025
+
+	dc.SetFontFace(r.fontFace(headerSize, false))
+	dc.SetRGBA(1, 1, 1, 0.45)
+
+	headerWidth, _ := dc.MeasureString("TASKS")
+	dc.DrawString("TASKS", x+width-headerWidth, y+headerSize)
+	y += headerSize * 3
+
+	if len(unavailable) > 0 {
+		dc.SetFontFace(r.fontFace(dueSize, true))
+		dc.SetRGBA(0.88, 0.72, 0.29, 1)
+		for _, line := range unavailable {
+			lineWidth, _ := dc.MeasureString(line)
+			dc.DrawString(line, x+width-lineWidth, y+dueSize)
+			y += dueSize * 1.6
+		}
+		y += dueSize * 0.6
+	}
+
+	maxTitleWidth := width * 0.6
+	boxX := x + width - maxTitleWidth - boxGap - boxSize
+	textX := boxX + boxSize + boxGap
+
+	for _, task := range data.Tasks {
+		if y+titleSize*2.5 > float64(opts.Height) {
+			break
+		}
+
+		// Priorities 1-4 are high and 5 is medium (RFC 5545).
+		title := task.Summary
+		switch {
+		case task.Priority >= 1 && task.Priority <= 4:
+			title = "!! " + title
+		case task.Priority == 5:
+			title = "! " + title
+		}
+
+		dc.SetFontFace(r.fontFace(titleSize, false))
 		lines := dc.WordWrap(title, maxTitleWidth)
 		if len(lines) > 2 {
 			lines = lines[:2]
 		}
 
-		currentTextY := badgeY + badgeHeight*0.45 + titleSize*0.35
+		due := task.DueLabel
+		if task.Due != nil && !task.AllDay {
+			t := *task.Due
+			if loc != nil {
+				t = t.In(loc)
+			}
+			due = strings.TrimSpace(due + " " + t.Format("15:04"))
+		}
+
+		rowHeight := titleSize * 1.2 * float64(len(lines))
+		if due != "" {
+			rowHeight += dueSize * 1.4
+		}
+
+		if c, ok := parseHexColor(task.Color); ok {
+			dc.SetColor(c)
+			dc.DrawRoundedRectangle(boxX-markerGap-markerWidth, y, markerWidth, rowHeight, markerWidth/2)
+			dc.Fill()
+		}
+
+		dc.SetRGBA(1, 1, 1, 0.7)
+		dc.SetLineWidth(1.5)
+		dc.DrawRoundedRectangle(boxX, y+titleSize*0.2, boxSize, boxSize, 2)
+		if task.Completed {
+			dc.Fill()
+		} else {
+			dc.Stroke()
+		}
+
+		if task.Completed {
+			dc.SetRGBA(1, 1, 1, 0.45)
+		} else {
+			dc.SetColor(color.White)
+		}
+		textY := y + titleSize
 		for _, line := range lines {
-			dc.DrawString(line, textXLocal, currentTextY)
-			currentTextY += titleSize * 1.2
+			dc.DrawString(line, textX, textY)
+			textY += titleSize * 1.2
 		}
 
-		if event.Location != "" {
-			dc.SetFontFace(r.fontFace(locationSize, true))
-			dc.SetRGBA(1, 1, 1, 0.6)
-			locY := badgeY + badgeHeight*0.75 + locationSize*0.35
-			if currentTextY > locY-locationSize {
-				locY = currentTextY + locationSize*0.2
+		if due != "" {
+			dc.SetFontFace(r.fontFace(dueSize, true))
+			if task.Overdue {
+				dc.SetRGBA(0.89, 0.36, 0.36, 1)
+			} else {
+				dc.SetRGBA(1, 1, 1, 0.6)
 			}
-			dc.DrawString(event.Location, textXLocal, locY)
+			dc.DrawString(due, textX, textY-titleSize*0.2+dueSize)
 		}
 
-		y += badgeHeight + padding*0.5
+		y += rowHeight + padding*0.4
 	}
 
 	return y - startY
 }
 
+func (r *GGRenderer) drawBirthdays(dc *gg.Context, opts RenderOptions, x, y, width float64, data *fetcher.BirthdayData) float64 {
+	var unavailable []string
+	for _, source := range data.Sources {
+		if source.State != fetcher.SourceOK {
+			name := source.Name
+			if name == "" {
+				name = "Contacts"
+			}
+			unavailable = append(unavailable, name+" unavailable")
+		}
+	}
+	if len(data.Birthdays) == 0 && len(unavailable) == 0 {
+		return 0
+	}
+
+	startY := y
+	headerSize := float64(opts.Height) * 0.012
+	nameSize := float64(opts.Height) * 0.02
+	detailSize := float64(opts.Height) * 0.015
+	rowPadding := float64(opts.Width) * 0.006
+	padding := float64(opts.Width) * 0.025
+
+	dc.SetFontFace(r.fontFace(headerSize, false))
+	dc.SetRGBA(1, 1, 1, 0.45)
+
+	headerWidth, _ := dc.MeasureString("BIRTHDAYS")
+	dc.DrawString("BIRTHDAYS", x+width-headerWidth, y+headerSize)
+	y += headerSize * 3
+
+	if len(unavailable) > 0 {
+		dc.SetFontFace(r.fontFace(detailSize, true))
+		dc.SetRGBA(0.88, 0.72, 0.29, 1)
+		for _, line := range unavailable {
+			lineWidth, _ := dc.MeasureString(line)
+			dc.DrawString(line, x+width-lineWidth, y+detailSize)
+			y += detailSize * 1.6
+		}
+		y += detailSize * 0.6
+	}
+
+	for _, b := range data.Birthdays {
+		rowHeight := nameSize*1.2 + detailSize*1.4
+		if y+rowHeight > float64(opts.Height) {
+			break
+		}
+
+		age := ""
+		switch {
+		case b.Kind == fetcher.KindAnniversary && b.Age > 0:
+			age = fmt.Sprintf("%d years", b.Age)
+		case b.Kind == fetcher.KindAnniversary:
+			age = "Anniversary"
+		case b.Age > 0:
+			age = fmt.Sprintf("turns %d", b.Age)
+		}
+		details := b.Label
+		if age != "" {
+			details += " · " + age
+		}
+
+		dc.SetFontFace(r.fontFace(nameSize, !b.Today))
+		nameWidth, _ := dc.MeasureString(b.Name)
+		dc.SetFontFace(r.fontFace(detailSize, true))
+		detailsWidth, _ := dc.MeasureString(details)
+
+		// Today's birthdays stand out on a badge like the calendar's.
+		if b.Today {
+			boxWidth := math.Max(nameWidth, detailsWidth) + rowPadding*2
+			dc.SetRGBA(1, 1, 1, 0.15)
+			dc.DrawRoundedRectangle(x+width-boxWidth+rowPadding, y-rowPadding, boxWidth, rowHeight+rowPadding*2, 4)
+			dc.Fill()
+		}
+
+		dc.SetFontFace(r.fontFace(nameSize, !b.Today))
+		if b.Today {
+			dc.SetRGBA(0.95, 0.76, 0.31, 1)
+		} else {
+			dc.SetColor(color.White)
+		}
+		dc.DrawString(b.Name, x+width-nameWidth, y+nameSize)
+
+		dc.SetFontFace(r.fontFace(detailSize, true))
+		dc.SetRGBA(1, 1, 1, 0.6)
+		dc.DrawString(details, x+width-detailsWidth, y+nameSize*1.2+detailSize)
+
+		y += rowHeight + padding*0.5
+	}
+
+	return y - startY
+}
+
+// maxAlerts is the number of alert banners drawn at once.
+const maxAlerts = 3
+
+// drawAlerts draws the due reminders as banners across the top of the
+// dashboard, over everything else, each with the time left until its event.
+func (r *GGRenderer) drawAlerts(dc *gg.Context, opts RenderOptions, data DashboardData) {
+	if len(data.Alerts) == 0 {
+		return
+	}
+
+	now := data.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	locale := textutil.DateLocale(data.Locale)
+	words := textutil.Countdown(data.Locale)
+
+	padding := float64(opts.Width) * 0.025
+	titleSize := float64(opts.Height) * 0.028
+	detailSize := float64(opts.Height) * 0.017
+	width := float64(opts.Width) * 0.5
+	height := titleSize*1.4 + detailSize*1.6 + padding*0.8
+	x := (float64(opts.Width) - width) / 2
+	y := padding * 0.6
+
+	for i, alert := range data.Alerts {
+		if i == maxAlerts {
+			break
+		}
+
+		dc.SetRGBA(0.08, 0.08, 0.08, 0.88)
+		dc.DrawRoundedRectangle(x, y, width, height, 8)
+		dc.Fill()
+		dc.SetRGBA(0.88, 0.72, 0.29, 1)
+		dc.SetLineWidth(3)
+		dc.DrawRoundedRectangle(x, y, width, height, 8)
+		dc.Stroke()
+
+		inset := padding * 0.5
+		textX := x + inset
+		if c, ok := parseHexColor(alert.Color); ok {
+			dc.SetColor(c)
+			dc.DrawRectangle(x+inset, y+inset, 5, height-inset*2)
+			dc.Fill()
+			textX += 5 + inset*0.6
+		}
+
+		countdown := words.Format(alert.Start.Sub(now))
+		dc.SetFontFace(r.fontFace(titleSize, false))
+		countdownWidth, _ := dc.MeasureString(countdown)
+		dc.SetRGBA(0.88, 0.72, 0.29, 1)
+		dc.DrawString(countdown, x+width-inset-countdownWidth, y+inset+titleSize)
+
+		title := alert.Summary
+		if lines := dc.WordWrap(title, x+width-inset*2-countdownWidth-textX); len(lines) > 0 {
+			title = lines[0]
+		}
+		dc.SetColor(color.White)
+		dc.DrawString(title, textX, y+inset+titleSize)
+
+		start := alert.Start
+		if data.Location != nil {
+			start = start.In(data.Location)
+		}
+		details := monday.Format(start, "15:04", locale)
+		if data.TimeFormat == "12h" {
+			details = monday.Format(start, "3:04 PM", locale)
+		}
+		for _, part := range []string{alert.Location, alert.Calendar} {
+			if part != "" {
+				details += " · " + part
+			}
+		}
+		dc.SetFontFace(r.fontFace(detailSize, true))
+		dc.SetRGBA(1, 1, 1, 0.7)
+		dc.DrawString(details, textX, y+inset+titleSize*1.4+detailSize)
+
+		y += height + padding*0.3
+	}
+}
+
+// parseHexColor parses a #rgb or #rrggbb color.
+func parseHexColor(s string) (color.Color, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return nil, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, true
+}
+
 func formatRelativeTime(t time.Time) string {
 	if t.IsZero() {
 		return ""
@@ -22,9 +22,12 @@ type WeatherData struct {
 }
 
 type NewsItem struct {
-	Title   string
-	Summary string
-	PubDate time.Time
+	Title     string
+	Summary   string
+	Source    string
+	PubDate   time.Time
+	Undated   bool
+	Thumbnail string
 }
 
 type CalendarEvent struct {
@@ -35,18 +38,37 @@ type CalendarEvent struct {
 	Location string
 }
 
+// Alert is a due calendar reminder, shown as a banner until the event
+// starts.
+type Alert struct {
+	Summary  string
+	Location string
+	Calendar string
+	Color    string
+	Start    time.Time
+}
+
 type DashboardData struct {
 	Config interface{}
 
 	SectionData map[string]interface{}
 
 	Time       time.Time
+	Location   *time.Location
 	Locale     string
 	TimeFormat string
 	Weather    *WeatherData
 	News       []NewsItem
 	Calendar   []CalendarEvent
 	Background image.Image
+
+	BackgroundCaption string
+
+	// Thumbnails holds decoded news thumbnails keyed by their URL.
+	Thumbnails map[string]image.Image
+
+	// Alerts are drawn over the dashboard, the soonest first.
+	Alerts []Alert
 }
 
 type Renderer interface {
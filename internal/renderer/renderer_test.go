@@ -3,8 +3,12 @@ package renderer
 import (
 	"context"
 	"image"
+	"image/color"
 	"testing"
 	"time"
+
+	"bros_kiosk/internal/config"
+	"bros_kiosk/pkg/fetcher"
 )
 
 func TestNewGGRenderer(t *testing.T) {
@@ -107,6 +111,83 @@ func TestGGRenderer_Render_WithNews(t *testing.T) {
 	}
 }
 
+func TestGGRenderer_Render_NewsThumbnail(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	for i := range thumb.Pix {
+		if i%4 == 0 || i%4 == 3 {
+			thumb.Pix[i] = 255
+		}
+	}
+
+	data := DashboardData{
+		Time: time.Now(),
+		Config: &config.Config{Sections: []config.Section{
+			{ID: "news", Type: "rss", Region: "top-left"},
+		}},
+		SectionData: map[string]interface{}{
+			"news": &fetcher.RSSData{Items: []fetcher.RSSItem{
+				{Title: "Headline", PubDate: time.Now(), Thumbnail: "http://example.com/t.jpg"},
+			}},
+		},
+		Thumbnails: map[string]image.Image{"http://example.com/t.jpg": thumb},
+	}
+
+	img, err := r.Render(context.Background(), DefaultOptions(), data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// The thumbnail sits at the left edge of the news column, next to the
+	// first headline.
+	c := color.RGBAModel.Convert(img.At(85, 133)).(color.RGBA)
+	if c.R < 200 || c.G > 50 || c.B > 50 {
+		t.Errorf("Expected red thumbnail pixel, got %v", c)
+	}
+}
+
+func TestGGRenderer_Render_QRSection(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	data := DashboardData{
+		Time: time.Now(),
+		Config: &config.Config{Sections: []config.Section{
+			{ID: "wifi", Type: "qr", Region: "top-left", QR: &config.QRConfig{Text: "https://example.com"}},
+		}},
+	}
+
+	img, err := r.Render(context.Background(), DefaultOptions(), data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// The tile is centered in the 576px column starting at x=48, y=72 and is
+	// 270px wide: white quiet zone around dark modules.
+	if c := color.RGBAModel.Convert(img.At(210, 80)).(color.RGBA); c.R != 255 || c.G != 255 || c.B != 255 {
+		t.Errorf("Expected white quiet zone, got %v", c)
+	}
+	dark, light := 0, 0
+	for y := 130; y < 290; y++ {
+		for x := 260; x < 420; x++ {
+			if c := color.GrayModel.Convert(img.At(x, y)).(color.Gray); c.Y < 50 {
+				dark++
+			} else if c.Y > 200 {
+				light++
+			}
+		}
+	}
+	if dark == 0 || light == 0 {
+		t.Errorf("Expected dark and light modules, got %d dark and %d light pixels", dark, light)
+	}
+}
+
 func TestGGRenderer_Render_WithCalendar(t *testing.T) {
 	r, err := NewGGRenderer()
 	if err != nil {
@@ -130,6 +211,208 @@ func TestGGRenderer_Render_WithCalendar(t *testing.T) {
 	}
 }
 
+func TestGGRenderer_Render_CalendarDays(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	now := time.Now()
+	data := DashboardData{
+		Time: now,
+		Config: &config.Config{Sections: []config.Section{
+			{ID: "cal", Type: "calendar", Region: "top-right"},
+		}},
+		SectionData: map[string]interface{}{
+			"cal": &fetcher.CalendarData{
+				Events: []fetcher.CalendarEvent{
+					{Summary: "Standup", Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), Calendar: "Work", Color: "#f00"},
+					{Summary: "Dentist", Start: now.Add(26 * time.Hour), End: now.Add(27 * time.Hour), Calendar: "Family", Color: "#00ff00"},
+				},
+				Days: []fetcher.CalendarDay{
+					{Date: now.Format("2006-01-02"), Label: "Today", Index: 0, Count: 1},
+					{Label: "Tomorrow", Index: 1, Count: 1},
+					// Out of range groups are skipped.
+					{Label: "Broken", Index: 1, Count: 5},
+				},
+			},
+		},
+	}
+
+	img, err := r.Render(context.Background(), DefaultOptions(), data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if img == nil {
+		t.Fatalf("Render() returned nil image")
+	}
+
+	// Each event is marked in the color of its calendar.
+	red, green := false, false
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			red = red || (c.R == 255 && c.G == 0 && c.B == 0)
+			green = green || (c.R == 0 && c.G == 255 && c.B == 0)
+		}
+	}
+	if !red || !green {
+		t.Errorf("Expected red and green calendar markers, got red=%v green=%v", red, green)
+	}
+}
+
+func TestGGRenderer_Render_CalendarUnavailable(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	data := DashboardData{
+		Time: time.Now(),
+		Config: &config.Config{Sections: []config.Section{
+			{ID: "cal", Type: "calendar", Region: "top-right"},
+		}},
+		SectionData: map[string]interface{}{
+			"cal": &fetcher.CalendarData{
+				Sources: []fetcher.CalendarSourceStatus{
+					{Name: "Home", State: fetcher.SourceOK},
+					{Name: "Work", State: fetcher.SourceError, Error: "timeout"},
+				},
+			},
+		},
+	}
+
+	img, err := r.Render(context.Background(), DefaultOptions(), data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// Without events the section still flags the failed source.
+	warned := false
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y && !warned; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); c.R > 200 && c.G > 160 && c.G < 200 && c.B < 100 {
+				warned = true
+				break
+			}
+		}
+	}
+	if !warned {
+		t.Error("Expected a warning for the unavailable calendar")
+	}
+}
+
+func TestGGRenderer_Render_TasksOverdue(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	tasks := &fetcher.TaskData{Tasks: []fetcher.Task{
+		{Summary: "File taxes", Due: &yesterday, AllDay: true, DueLabel: "Yesterday", Priority: 1},
+	}}
+	data := DashboardData{
+		Time: time.Now(),
+		Config: &config.Config{Sections: []config.Section{
+			{ID: "todo", Type: "tasks", Region: "top-right"},
+		}},
+		SectionData: map[string]interface{}{"todo": tasks},
+	}
+
+	hasRed := func() bool {
+		img, err := r.Render(context.Background(), DefaultOptions(), data)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); c.R > 200 && c.G < 120 && c.B < 120 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if hasRed() {
+		t.Error("Expected no overdue highlight for a task that is not overdue")
+	}
+	tasks.Tasks[0].Overdue = true
+	if !hasRed() {
+		t.Error("Expected the due date of an overdue task in red")
+	}
+}
+
+func TestGGRenderer_Render_BirthdayToday(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	birthdays := &fetcher.BirthdayData{Birthdays: []fetcher.Birthday{
+		{Name: "Jane Doe", Kind: fetcher.KindBirthday, Label: "Tomorrow", Age: 41, DaysUntil: 1},
+	}}
+	data := DashboardData{
+		Time: time.Now(),
+		Config: &config.Config{Sections: []config.Section{
+			{ID: "bday", Type: "birthdays", Region: "top-right"},
+		}},
+		SectionData: map[string]interface{}{"bday": birthdays},
+	}
+
+	hasGold := func() bool {
+		img, err := r.Render(context.Background(), DefaultOptions(), data)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); c.R > 200 && c.G > 150 && c.B < 100 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if hasGold() {
+		t.Error("Expected no highlight for an upcoming birthday")
+	}
+	birthdays.Birthdays[0].Today, birthdays.Birthdays[0].Label = true, "Today"
+	if !hasGold() {
+		t.Error("Expected today's birthday to be highlighted")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+		ok   bool
+	}{
+		{"#4a90d9", color.RGBA{0x4a, 0x90, 0xd9, 255}, true},
+		{"#F0A", color.RGBA{0xff, 0x00, 0xaa, 255}, true},
+		{"", color.RGBA{}, false},
+		{"#12345", color.RGBA{}, false},
+		{"#zzzzzz", color.RGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseHexColor(tt.in)
+		if ok != tt.ok {
+			t.Errorf("parseHexColor(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("parseHexColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestGGRenderer_Render_WithBackground(t *testing.T) {
 	r, err := NewGGRenderer()
 	if err != nil {
@@ -164,3 +447,41 @@ func TestDefaultOptions(t *testing.T) {
 		t.Errorf("Format = %s, want png", opts.Format)
 	}
 }
+
+func TestGGRenderer_Render_Alerts(t *testing.T) {
+	r, err := NewGGRenderer()
+	if err != nil {
+		t.Fatalf("NewGGRenderer() error = %v", err)
+	}
+
+	now := time.Now()
+	data := DashboardData{
+		Time:   now,
+		Config: &config.Config{},
+	}
+
+	// The banner's amber border is drawn across the top.
+	hasAmber := func() bool {
+		img, err := r.Render(context.Background(), DefaultOptions(), data)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Min.Y+b.Dy()/4; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); c.R > 200 && c.G > 150 && c.B < 100 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if hasAmber() {
+		t.Error("Expected no banner without alerts")
+	}
+	data.Alerts = []Alert{{Summary: "School run", Location: "Main St", Start: now.Add(12 * time.Minute)}}
+	if !hasAmber() {
+		t.Error("Expected an alert banner")
+	}
+}
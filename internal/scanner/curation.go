@@ -0,0 +1,169 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// PhotoID returns a stable identifier for a photo path that is safe to use in URLs.
+func PhotoID(path string) string {
+	hash := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(hash[:8])
+}
+
+// fingerprintBytes is how much of a photo is read for its content key. The
+// headers and first scanlines together with the file size tell photos apart
+// without reading the whole library.
+const fingerprintBytes = 64 << 10
+
+// Curation persists per-photo decisions (hidden, favorite) on disk so they
+// survive rescans and restarts. Decisions are keyed by the photo's content,
+// so they follow it when the library is moved or renamed.
+type Curation struct {
+	path    string
+	entries map[string]curationEntry
+	mu      sync.RWMutex
+
+	// keys caches content keys by path until the file changes.
+	keys   map[string]contentKey
+	keysMu sync.Mutex
+}
+
+type contentKey struct {
+	size    int64
+	modTime int64
+	key     string
+}
+
+type curationEntry struct {
+	Path     string `json:"path"`
+	Hidden   bool   `json:"hidden,omitempty"`
+	Favorite bool   `json:"favorite,omitempty"`
+}
+
+// NewCuration loads the curation file at path, starting empty if it does not exist.
+func NewCuration(path string) (*Curation, error) {
+	c := &Curation{
+		path:    path,
+		entries: make(map[string]curationEntry),
+		keys:    make(map[string]contentKey),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Curation) IsHidden(path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lookup(path).Hidden
+}
+
+func (c *Curation) IsFavorite(path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lookup(path).Favorite
+}
+
+func (c *Curation) SetHidden(path string, hidden bool) error {
+	return c.update(path, func(e *curationEntry) { e.Hidden = hidden })
+}
+
+func (c *Curation) SetFavorite(path string, favorite bool) error {
+	return c.update(path, func(e *curationEntry) { e.Favorite = favorite })
+}
+
+func (c *Curation) update(path string, fn func(*curationEntry)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.key(path)
+	entry := c.lookup(path)
+	delete(c.entries, PhotoID(path))
+	entry.Path = path
+	fn(&entry)
+
+	if !entry.Hidden && !entry.Favorite {
+		delete(c.entries, id)
+	} else {
+		c.entries[id] = entry
+	}
+
+	return c.save()
+}
+
+// lookup returns the decisions about a photo. Entries stored under PhotoID
+// before decisions were keyed by content are still found by path.
+func (c *Curation) lookup(path string) curationEntry {
+	if entry, ok := c.entries[c.key(path)]; ok {
+		return entry
+	}
+	return c.entries[PhotoID(path)]
+}
+
+// key returns the content key of a photo: a hash of its size and first
+// fingerprintBytes. Paths that cannot be read locally, such as S3 keys,
+// fall back to PhotoID.
+func (c *Curation) key(path string) string {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return PhotoID(path)
+	}
+
+	c.keysMu.Lock()
+	cached, ok := c.keys[path]
+	c.keysMu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime == info.ModTime().UnixNano() {
+		return cached.key
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return PhotoID(path)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	binary.Write(hash, binary.BigEndian, info.Size())
+	if _, err := io.CopyN(hash, f, fingerprintBytes); err != nil && err != io.EOF {
+		return PhotoID(path)
+	}
+	key := hex.EncodeToString(hash.Sum(nil)[:8])
+
+	c.keysMu.Lock()
+	c.keys[path] = contentKey{size: info.Size(), modTime: info.ModTime().UnixNano(), key: key}
+	c.keysMu.Unlock()
+	return key
+}
+
+func (c *Curation) save() error {
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
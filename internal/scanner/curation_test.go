@@ -0,0 +1,186 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCuration_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "curation.json")
+
+	c, err := NewCuration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetHidden("/photos/blurry.jpg", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetFavorite("/photos/beach.jpg", true); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewCuration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.IsHidden("/photos/blurry.jpg") {
+		t.Error("Expected blurry.jpg to stay hidden after reload")
+	}
+	if !reloaded.IsFavorite("/photos/beach.jpg") {
+		t.Error("Expected beach.jpg to stay a favorite after reload")
+	}
+
+	if err := reloaded.SetHidden("/photos/blurry.jpg", false); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.IsHidden("/photos/blurry.jpg") {
+		t.Error("Expected blurry.jpg to be visible again")
+	}
+}
+
+func TestManager_Curation(t *testing.T) {
+	c, err := NewCuration(filepath.Join(t.TempDir(), "curation.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetHidden("hidden.jpg", true)
+	c.SetFavorite("fav.jpg", true)
+
+	mgr := NewManager(&MockScanner{Files: []string{"hidden.jpg", "fav.jpg", "plain.jpg"}})
+	mgr.SetCuration(c)
+	if err := mgr.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	photos := mgr.GetPhotos()
+	if len(photos) != 2 {
+		t.Fatalf("Expected 2 visible photos, got %v", photos)
+	}
+
+	if p, ok := mgr.Lookup(PhotoID("hidden.jpg")); !ok || p != "hidden.jpg" {
+		t.Errorf("Expected Lookup to resolve hidden photo, got %q %v", p, ok)
+	}
+
+	mgr.SetShuffle(true)
+	counts := map[string]int{}
+	for i := 0; i < 400; i++ {
+		p, ok := mgr.Next()
+		if !ok {
+			t.Fatal("Expected a photo")
+		}
+		counts[p]++
+	}
+	if counts["hidden.jpg"] != 0 {
+		t.Errorf("Hidden photo was selected %d times", counts["hidden.jpg"])
+	}
+	if counts["fav.jpg"] == 0 || counts["plain.jpg"] == 0 {
+		t.Errorf("Expected both visible photos to be selected, got %v", counts)
+	}
+
+	recent := mgr.Recent()
+	if len(recent) != 2 || recent[0].Path != mgr.Current() {
+		t.Errorf("Unexpected recent list: %+v", recent)
+	}
+}
+
+func TestManager_NextSequential(t *testing.T) {
+	mgr := NewManager(&MockScanner{Files: []string{"a.jpg", "b.jpg"}})
+	if _, ok := mgr.Next(); ok {
+		t.Error("Expected no photo before scan")
+	}
+	mgr.Scan(context.Background())
+
+	expected := []string{"a.jpg", "b.jpg", "a.jpg"}
+	for i, want := range expected {
+		if got, _ := mgr.Next(); got != want {
+			t.Errorf("Step %d: expected %s, got %s", i, want, got)
+		}
+	}
+}
+
+func TestCuration_FollowsMovedPhoto(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "library", "beach.jpg")
+	if err := os.MkdirAll(filepath.Dir(old), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(old, []byte("beach photo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.jpg"), []byte("other photo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "curation.json")
+	c, err := NewCuration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetFavorite(old, true); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := filepath.Join(dir, "moved", "beach.jpg")
+	if err := os.MkdirAll(filepath.Dir(moved), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(old, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewCuration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.IsFavorite(moved) {
+		t.Error("Expected the favorite to follow the moved photo")
+	}
+	if reloaded.IsFavorite(filepath.Join(dir, "other.jpg")) {
+		t.Error("Expected other photos to be unaffected")
+	}
+}
+
+func TestCuration_PathKeyedEntries(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "blurry.jpg")
+	if err := os.WriteFile(photo, []byte("blurry photo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "curation.json")
+	legacy := `{"` + PhotoID(photo) + `": {"path": "` + photo + `", "hidden": true}}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCuration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsHidden(photo) {
+		t.Fatal("Expected a path-keyed decision to still apply")
+	}
+	if err := c.SetFavorite(photo, true); err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsHidden(photo) || !c.IsFavorite(photo) {
+		t.Error("Expected earlier decisions to be kept when rekeyed by content")
+	}
+	if _, ok := c.entries[PhotoID(photo)]; ok {
+		t.Error("Expected the path-keyed entry to be replaced")
+	}
+}
+
+func TestManager_NextForDisplays(t *testing.T) {
+	mgr := NewManager(&MockScanner{Files: []string{"a.jpg", "b.jpg", "c.jpg"}})
+	mgr.Scan(context.Background())
+
+	for i, want := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		for _, client := range []string{"kitchen", "hall"} {
+			if got, _ := mgr.NextFor(client); got != want {
+				t.Errorf("Step %d on %s: expected %s, got %s", i, client, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"bros_kiosk/internal/images"
+)
+
+const hashSaveEvery = 50
+
+// Deduper groups photos whose perceptual hashes are within MaxDistance bits
+// of each other.
+type Deduper struct {
+	store       *images.HashStore
+	maxDistance int
+}
+
+func NewDeduper(store *images.HashStore, maxDistance int) *Deduper {
+	return &Deduper{store: store, maxDistance: maxDistance}
+}
+
+// Groups returns the sets of near-duplicate photos. Only groups with more
+// than one member are returned; members are ordered largest file first.
+func (d *Deduper) Groups(ctx context.Context, photos []string) [][]string {
+	type hashed struct {
+		path string
+		hash uint64
+		size int64
+	}
+
+	items := make([]hashed, 0, len(photos))
+	for i, p := range photos {
+		if ctx.Err() != nil {
+			break
+		}
+		if hash, size, ok := d.store.Hash(p); ok {
+			items = append(items, hashed{path: p, hash: hash, size: size})
+		}
+		if (i+1)%hashSaveEvery == 0 {
+			d.save()
+		}
+	}
+	d.save()
+
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i := 0; i < len(items); i++ {
+		for j := i + 1; j < len(items); j++ {
+			if images.HammingDistance(items[i].hash, items[j].hash) <= d.maxDistance {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	byRoot := make(map[int][]hashed)
+	for i, it := range items {
+		root := find(i)
+		byRoot[root] = append(byRoot[root], it)
+	}
+
+	groups := make([][]string, 0)
+	for _, members := range byRoot {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].size != members[j].size {
+				return members[i].size > members[j].size
+			}
+			return members[i].path < members[j].path
+		})
+		group := make([]string, len(members))
+		for i, m := range members {
+			group[i] = m.path
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func (d *Deduper) save() {
+	if err := d.store.Save(); err != nil {
+		slog.Warn("Failed to persist photo hashes", "error", err)
+	}
+}
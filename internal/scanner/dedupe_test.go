@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"bros_kiosk/internal/images"
+
+	"github.com/disintegration/imaging"
+)
+
+func saveTestImage(t *testing.T, path string, w, h int, invert bool) {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if invert {
+				v = 255 - v
+			}
+			img.Set(x, y, color.NRGBA{v, v, v, 255})
+		}
+	}
+	if err := imaging.Save(img, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManager_Dedupe(t *testing.T) {
+	dir := t.TempDir()
+	big := filepath.Join(dir, "burst1.png")
+	small := filepath.Join(dir, "burst2.png")
+	other := filepath.Join(dir, "other.png")
+	saveTestImage(t, big, 200, 100, false)
+	saveTestImage(t, small, 100, 50, false)
+	saveTestImage(t, other, 200, 100, true)
+
+	store, err := images.NewHashStore(filepath.Join(dir, "phash.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	curation, err := NewCuration(filepath.Join(dir, "curation.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(&MockScanner{Files: []string{small, big, other, filepath.Join(dir, "missing.jpg")}})
+	mgr.SetCuration(curation)
+	mgr.SetDeduper(NewDeduper(store, 4))
+	if err := mgr.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	photos := mgr.GetPhotos()
+	if len(photos) != 3 {
+		t.Fatalf("Expected burst to collapse to one photo, got %v", photos)
+	}
+	for _, p := range photos {
+		if p == small {
+			t.Errorf("Expected the larger burst photo to represent the group, got %v", photos)
+		}
+	}
+	if d := mgr.Duplicates(big); len(d) != 1 || d[0] != small {
+		t.Errorf("Expected %s as duplicate of %s, got %v", small, big, d)
+	}
+
+	// A favorite wins the group; hiding it falls back to the next member.
+	curation.SetFavorite(small, true)
+	if !contains(mgr.GetPhotos(), small) || contains(mgr.GetPhotos(), big) {
+		t.Errorf("Expected favorite to represent the group, got %v", mgr.GetPhotos())
+	}
+	curation.SetHidden(small, true)
+	if !contains(mgr.GetPhotos(), big) {
+		t.Errorf("Expected group to fall back to %s, got %v", big, mgr.GetPhotos())
+	}
+}
@@ -0,0 +1,247 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bros_kiosk/pkg/fetcher"
+)
+
+const (
+	feedIndexFile    = "index.json"
+	maxFeedImageSize = 20 << 20
+	defaultFeedLimit = 10
+)
+
+// FeedScanner downloads the images attached to an RSS/Media RSS feed into a
+// local directory and keeps a rolling window of the most recent ones.
+type FeedScanner struct {
+	URL   string
+	Dir   string
+	Limit int
+
+	client   *http.Client
+	captions map[string]string
+	mu       sync.RWMutex
+}
+
+type feedEntry struct {
+	File  string `json:"file"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+func NewFeedScanner(url, dir string, limit int) *FeedScanner {
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	return &FeedScanner{
+		URL:      url,
+		Dir:      dir,
+		Limit:    limit,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		captions: make(map[string]string),
+	}
+}
+
+func (s *FeedScanner) Scan(ctx context.Context) ([]string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	previous := s.loadIndex()
+
+	feedImages, err := s.fetchFeed(ctx)
+	if err != nil {
+		if len(previous) == 0 {
+			return nil, err
+		}
+		slog.Warn("Photo feed unavailable, keeping cached images", "url", s.URL, "error", err)
+		return s.publish(previous), nil
+	}
+
+	known := make(map[string]feedEntry, len(previous))
+	for _, e := range previous {
+		known[e.URL] = e
+	}
+
+	window := make([]feedEntry, 0, s.Limit)
+	for _, img := range feedImages {
+		if len(window) >= s.Limit {
+			break
+		}
+		entry, ok := known[img.URL]
+		if !ok {
+			file, err := s.download(ctx, img.URL)
+			if err != nil {
+				slog.Warn("Failed to download feed image", "url", img.URL, "error", err)
+				continue
+			}
+			entry = feedEntry{File: file, URL: img.URL}
+		}
+		entry.Title = img.Title
+		window = append(window, entry)
+	}
+
+	kept := make(map[string]bool, len(window))
+	for _, e := range window {
+		kept[e.File] = true
+	}
+	for _, e := range previous {
+		if !kept[e.File] {
+			os.Remove(filepath.Join(s.Dir, e.File))
+		}
+	}
+
+	if err := s.saveIndex(window); err != nil {
+		slog.Warn("Failed to persist photo feed index", "dir", s.Dir, "error", err)
+	}
+
+	return s.publish(window), nil
+}
+
+// Caption returns the feed item title for a photo returned by Scan.
+func (s *FeedScanner) Caption(path string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.captions[path]
+}
+
+func (s *FeedScanner) publish(entries []feedEntry) []string {
+	files := make([]string, 0, len(entries))
+	captions := make(map[string]string, len(entries))
+	for _, e := range entries {
+		p := filepath.Join(s.Dir, e.File)
+		files = append(files, p)
+		captions[p] = e.Title
+	}
+
+	s.mu.Lock()
+	s.captions = captions
+	s.mu.Unlock()
+
+	return files
+}
+
+func (s *FeedScanner) fetchFeed(ctx context.Context) ([]fetcher.FeedImage, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return fetcher.ParseFeedImages(resp.Body, resp.Header.Get("Content-Type"))
+}
+
+func (s *FeedScanner) download(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("unexpected content type %q", contentType)
+	}
+
+	hash := sha256.Sum256([]byte(url))
+	file := hex.EncodeToString(hash[:8]) + feedImageExt(url, contentType)
+
+	tmp, err := os.CreateTemp(s.Dir, "download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxFeedImageSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if n > maxFeedImageSize {
+		return "", fmt.Errorf("image exceeds %d bytes", maxFeedImageSize)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.Dir, file)); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+func (s *FeedScanner) loadIndex() []feedEntry {
+	data, err := os.ReadFile(filepath.Join(s.Dir, feedIndexFile))
+	if err != nil {
+		return nil
+	}
+
+	var entries []feedEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil
+	}
+
+	valid := entries[:0]
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(s.Dir, e.File)); err == nil {
+			valid = append(valid, e)
+		}
+	}
+	return valid
+}
+
+func (s *FeedScanner) saveIndex(entries []feedEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.Dir, feedIndexFile), data, 0644)
+}
+
+func feedImageExt(url, contentType string) string {
+	ext := strings.ToLower(path.Ext(strings.SplitN(url, "?", 2)[0]))
+	if SupportedExts[ext] {
+		return ext
+	}
+
+	switch strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".jpg"
+}
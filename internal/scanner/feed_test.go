@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFeedScanner_Scan(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []string{"a", "b", "c"}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/feed" {
+			fmt.Fprint(w, `<?xml version="1.0"?><rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel>`)
+			for _, id := range items {
+				fmt.Fprintf(w, `<item><title>Picture %s</title><media:content url="%s/img/%s.png" medium="image"/></item>`, id, server.URL, id)
+			}
+			fmt.Fprintf(w, `<item><title>No image</title><enclosure url="%s/audio.mp3" type="audio/mpeg"/></item></channel></rss>`, server.URL)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	s := NewFeedScanner(server.URL+"/feed", dir, 2)
+
+	files, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d: %v", len(files), files)
+	}
+	if got := s.Caption(files[0]); got != "Picture a" {
+		t.Errorf("Expected caption 'Picture a', got %q", got)
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Errorf("Expected downloaded file %s: %v", f, err)
+		}
+	}
+
+	// A new item pushes the oldest one out of the window.
+	items = []string{"d", "a", "b", "c"}
+	files2, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Second scan failed: %v", err)
+	}
+	if len(files2) != 2 || s.Caption(files2[0]) != "Picture d" || files2[1] != files[0] {
+		t.Errorf("Unexpected window after rescan: %v", files2)
+	}
+	if _, err := os.Stat(files[1]); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be evicted", files[1])
+	}
+
+	// When the feed is unreachable the cached window is served.
+	server.Close()
+	files3, err := NewFeedScanner(server.URL+"/feed", dir, 2).Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Offline scan failed: %v", err)
+	}
+	if len(files3) != 2 || filepath.Dir(files3[0]) != dir {
+		t.Errorf("Expected cached files, got %v", files3)
+	}
+}
+
+func TestFeedScanner_ScanError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s := NewFeedScanner(server.URL, t.TempDir(), 0)
+	if _, err := s.Scan(context.Background()); err == nil {
+		t.Error("Expected error for failing feed without cache, got nil")
+	}
+}
+
+func TestFeedScanner_ContentTypeCharset(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/feed" {
+			w.Header().Set("Content-Type", "application/rss+xml; charset=windows-1251")
+			fmt.Fprintf(w, "<rss><channel><item><title>\xcc\xee\xf0\xe5</title><enclosure url=\"%s/sea.png\" type=\"image/png\"/></item></channel></rss>", server.URL)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	s := NewFeedScanner(server.URL+"/feed", t.TempDir(), 1)
+	files, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(files) != 1 || s.Caption(files[0]) != "Море" {
+		t.Errorf("Expected transcoded caption, got %v", files)
+	}
+}
@@ -2,12 +2,36 @@ package scanner
 
 import (
 	"context"
+	"math/rand"
 	"sync"
+	"time"
 )
 
+// FavoriteWeight is how many times more likely a favorite photo is to be
+// picked than a regular one when shuffling.
+const FavoriteWeight = 3
+
+const recentLimit = 24
+
+// ShownPhoto records a photo handed out by Next.
+type ShownPhoto struct {
+	Path    string
+	ShownAt time.Time
+}
+
 type Manager struct {
 	scanners []Scanner
 	photos   []string
+	captions map[string]string
+	curation *Curation
+	deduper  *Deduper
+	groupOf  map[string][]string
+	shuffle  bool
+	displays map[string]*display
+	added    []string
+	current  string
+	queue    []string
+	recent   []ShownPhoto
 	mu       sync.RWMutex
 }
 
@@ -15,15 +39,49 @@ func NewManager(scanners ...Scanner) *Manager {
 	return &Manager{
 		scanners: scanners,
 		photos:   make([]string, 0),
+		captions: make(map[string]string),
+		groupOf:  make(map[string][]string),
+		displays: make(map[string]*display),
 	}
 }
 
+// display is where one client is in the slideshow, so several displays
+// each walk through every photo instead of sharing one cursor.
+type display struct {
+	cursor int
+	last   string
+}
+
+// SetCuration enables hide/favorite decisions for photo selection.
+func (m *Manager) SetCuration(c *Curation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.curation = c
+}
+
+// SetDeduper enables near-duplicate detection; only one photo per group of
+// similar photos is eligible for display.
+func (m *Manager) SetDeduper(d *Deduper) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deduper = d
+}
+
+// SetShuffle switches Next between weighted random and sequential order.
+func (m *Manager) SetShuffle(shuffle bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shuffle = shuffle
+}
+
 func (m *Manager) Scan(ctx context.Context) error {
 	var allPhotos []string
+	captions := make(map[string]string)
 
 	type result struct {
-		files []string
-		err   error
+		scanner Scanner
+		files   []string
+		err     error
 	}
 
 	ch := make(chan result, len(m.scanners))
@@ -34,7 +92,7 @@ func (m *Manager) Scan(ctx context.Context) error {
 		go func(sc Scanner) {
 			defer wg.Done()
 			files, err := sc.Scan(ctx)
-			ch <- result{files: files, err: err}
+			ch <- result{scanner: sc, files: files, err: err}
 		}(s)
 	}
 
@@ -46,18 +104,281 @@ func (m *Manager) Scan(ctx context.Context) error {
 			return res.err
 		}
 		allPhotos = append(allPhotos, res.files...)
+
+		if c, ok := res.scanner.(Captioner); ok {
+			for _, f := range res.files {
+				if caption := c.Caption(f); caption != "" {
+					captions[f] = caption
+				}
+			}
+		}
 	}
 
 	m.mu.Lock()
+	// Photos added while the scanners ran may be missing from their
+	// results; the next scan finds them on its own.
+	for _, p := range m.added {
+		if !contains(allPhotos, p) {
+			allPhotos = append(allPhotos, p)
+		}
+	}
+	m.added = nil
 	m.photos = allPhotos
+	m.captions = captions
+	deduper := m.deduper
 	m.mu.Unlock()
+
+	// Hashing can take a while on first run, so photos are published
+	// before duplicates are grouped.
+	if deduper != nil {
+		groupOf := make(map[string][]string)
+		for _, group := range deduper.Groups(ctx, allPhotos) {
+			for _, p := range group {
+				groupOf[p] = group
+			}
+		}
+
+		m.mu.Lock()
+		m.groupOf = groupOf
+		m.mu.Unlock()
+	}
 	return nil
 }
 
+// GetPhotos returns all photos eligible for display: not hidden and not
+// shadowed by a near-duplicate.
 func (m *Manager) GetPhotos() []string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	dst := make([]string, len(m.photos))
-	copy(dst, m.photos)
+	return m.visible()
+}
+
+func (m *Manager) visible() []string {
+	dst := make([]string, 0, len(m.photos))
+	for _, p := range m.photos {
+		if m.hidden(p) {
+			continue
+		}
+		if group, ok := m.groupOf[p]; ok && m.representative(group) != p {
+			continue
+		}
+		dst = append(dst, p)
+	}
+	return dst
+}
+
+func (m *Manager) hidden(path string) bool {
+	return m.curation != nil && m.curation.IsHidden(path)
+}
+
+// representative picks the photo shown for a group of duplicates: the first
+// visible favorite, otherwise the first visible member.
+func (m *Manager) representative(group []string) string {
+	first := ""
+	for _, p := range group {
+		if m.hidden(p) {
+			continue
+		}
+		if m.curation != nil && m.curation.IsFavorite(p) {
+			return p
+		}
+		if first == "" {
+			first = p
+		}
+	}
+	return first
+}
+
+// Duplicates returns the other members of the photo's near-duplicate group.
+func (m *Manager) Duplicates(path string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var dst []string
+	for _, p := range m.groupOf[path] {
+		if p != path {
+			dst = append(dst, p)
+		}
+	}
 	return dst
 }
+
+// Lookup resolves a PhotoID to its path, including hidden photos.
+func (m *Manager) Lookup(id string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.photos {
+		if PhotoID(p) == id {
+			return p, true
+		}
+	}
+	for _, s := range m.recent {
+		if PhotoID(s.Path) == id {
+			return s.Path, true
+		}
+	}
+	return "", false
+}
+
+// Next picks the next photo to display and records it as recently shown.
+// With shuffle enabled favorites are FavoriteWeight times more likely.
+func (m *Manager) Next() (string, bool) {
+	return m.NextFor("")
+}
+
+// NextFor is Next for one of several displays: each client keeps its own
+// place in the slideshow.
+func (m *Manager) NextFor(client string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	photos := m.visible()
+	if len(photos) == 0 {
+		return "", false
+	}
+
+	d, ok := m.displays[client]
+	if !ok {
+		d = &display{}
+		m.displays[client] = d
+	}
+
+	// Queued photos jump ahead of the regular order.
+	photo := m.dequeue(photos)
+	if photo == "" {
+		if m.shuffle {
+			photo = m.pickWeighted(photos, d.last)
+		} else {
+			photo = photos[d.cursor%len(photos)]
+			d.cursor = (d.cursor + 1) % len(photos)
+		}
+	}
+
+	d.last = photo
+	m.current = photo
+	m.remember(photo)
+	return photo, true
+}
+
+// Add makes a new photo available immediately, without waiting for a rescan.
+func (m *Manager) Add(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !contains(m.photos, path) {
+		m.photos = append(m.photos, path)
+	}
+	m.added = append(m.added, path)
+}
+
+// Enqueue moves a photo to the front of the playlist.
+func (m *Manager) Enqueue(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = append(m.queue, path)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Current returns the photo last handed out by Next.
+func (m *Manager) Current() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+func (m *Manager) dequeue(photos []string) string {
+	for len(m.queue) > 0 {
+		queued := m.queue[0]
+		m.queue = m.queue[1:]
+		if contains(photos, queued) {
+			return queued
+		}
+	}
+	return ""
+}
+
+func (m *Manager) pickWeighted(photos []string, last string) string {
+	weights := make([]int, len(photos))
+	total := 0
+	for i, p := range photos {
+		w := 1
+		if m.curation != nil && m.curation.IsFavorite(p) {
+			w = FavoriteWeight
+		}
+		// Avoid showing the same photo twice in a row.
+		if p == last && len(photos) > 1 {
+			w = 0
+		}
+		weights[i] = w
+		total += w
+	}
+
+	r := rand.Intn(total)
+	for i, w := range weights {
+		if r < w {
+			return photos[i]
+		}
+		r -= w
+	}
+	return photos[len(photos)-1]
+}
+
+func (m *Manager) remember(photo string) {
+	for i, s := range m.recent {
+		if s.Path == photo {
+			m.recent = append(m.recent[:i], m.recent[i+1:]...)
+			break
+		}
+	}
+	m.recent = append([]ShownPhoto{{Path: photo, ShownAt: time.Now()}}, m.recent...)
+	if len(m.recent) > recentLimit {
+		m.recent = m.recent[:recentLimit]
+	}
+}
+
+// Recent returns recently shown photos, newest first.
+func (m *Manager) Recent() []ShownPhoto {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dst := make([]ShownPhoto, len(m.recent))
+	copy(dst, m.recent)
+	return dst
+}
+
+// GetCaptions returns the known captions keyed by photo path.
+func (m *Manager) GetCaptions() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dst := make(map[string]string, len(m.captions))
+	for k, v := range m.captions {
+		dst[k] = v
+	}
+	return dst
+}
+
+// Caption returns the caption of a single photo, if any.
+func (m *Manager) Caption(path string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.captions[path]
+}
+
+// IsFavorite reports whether the photo is marked as a favorite.
+func (m *Manager) IsFavorite(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.curation != nil && m.curation.IsFavorite(path)
+}
+
+// IsHidden reports whether the photo is hidden from the slideshow.
+func (m *Manager) IsHidden(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hidden(path)
+}
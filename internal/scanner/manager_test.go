@@ -38,3 +38,55 @@ func TestManager_Scan(t *testing.T) {
 		}
 	}
 }
+
+type captionedScanner struct {
+	MockScanner
+}
+
+func (c *captionedScanner) Caption(path string) string {
+	return "caption for " + path
+}
+
+func TestManager_Captions(t *testing.T) {
+	mgr := NewManager(&MockScanner{Files: []string{"plain.jpg"}}, &captionedScanner{MockScanner{Files: []string{"feed.jpg"}}})
+	if err := mgr.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := mgr.Caption("feed.jpg"); got != "caption for feed.jpg" {
+		t.Errorf("Expected caption for feed.jpg, got %q", got)
+	}
+	if got := mgr.Caption("plain.jpg"); got != "" {
+		t.Errorf("Expected no caption for plain.jpg, got %q", got)
+	}
+	if len(mgr.GetCaptions()) != 1 {
+		t.Errorf("Expected 1 caption, got %d", len(mgr.GetCaptions()))
+	}
+}
+
+// addingScanner adds a photo to the manager while its scan is running, as
+// an upload during a rescan does.
+type addingScanner struct {
+	mgr  *Manager
+	path string
+}
+
+func (a *addingScanner) Scan(ctx context.Context) ([]string, error) {
+	a.mgr.Add(a.path)
+	return []string{"old.jpg"}, nil
+}
+
+func TestManager_AddDuringScan(t *testing.T) {
+	s := &addingScanner{path: "upload.jpg"}
+	mgr := NewManager(s)
+	s.mgr = mgr
+	if err := mgr.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	photos := mgr.GetPhotos()
+	sort.Strings(photos)
+	if len(photos) != 2 || photos[0] != "old.jpg" || photos[1] != "upload.jpg" {
+		t.Errorf("Expected the added photo to survive the scan, got %v", photos)
+	}
+}
@@ -6,6 +6,11 @@ type Scanner interface {
 	Scan(ctx context.Context) ([]string, error)
 }
 
+// Captioner is implemented by scanners that know a caption for their photos.
+type Captioner interface {
+	Caption(path string) string
+}
+
 var SupportedExts = map[string]bool{
 	".jpg":  true,
 	".jpeg": true,
@@ -9,13 +9,15 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
 func (s *DashboardServer) PhotosListHandler(w http.ResponseWriter, r *http.Request) {
 	photos := s.scannerMgr.GetPhotos()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"photos": photos,
+		"photos":   photos,
+		"captions": s.scannerMgr.GetCaptions(),
 	})
 }
 
@@ -93,9 +95,15 @@ func (s *DashboardServer) UpdateHandler(w http.ResponseWriter, r *http.Request)
 			}
 		}
 	}
+	photoSkips := s.photoSkips
+	alerts := s.alertsLocked(time.Now())
 	s.mu.RUnlock()
 
-	fullHash, err := hashing.Hash(updates)
+	fullHash, err := hashing.Hash(map[string]interface{}{
+		"updates":     updates,
+		"photo_skips": photoSkips,
+		"alerts":      alerts,
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -107,9 +115,11 @@ func (s *DashboardServer) UpdateHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	response := map[string]interface{}{
-		"status":  "ok",
-		"hash":    fullHash,
-		"updates": updates,
+		"status":      "ok",
+		"hash":        fullHash,
+		"updates":     updates,
+		"photo_skips": photoSkips,
+		"alerts":      alerts,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"bros_kiosk/pkg/fetcher"
+)
+
+// CalendarExportHandler serves the merged events of a calendar section as
+// /api/calendars/<id>.ics or /api/calendars/<id>.json: every event of the
+// section's window, whether or not the dashboard's display limits show it.
+// A section with an export_token requires it as a bearer token or token
+// parameter.
+func (s *DashboardServer) CalendarExportHandler(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	ext := path.Ext(file)
+	id := strings.TrimSuffix(file, ext)
+	if ext != ".ics" && ext != ".json" {
+		http.NotFound(w, r)
+		return
+	}
+
+	token, ok := s.calendarExportToken(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if token != "" && subtle.ConstantTimeCompare([]byte(requestToken(r)), []byte(token)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.RLock()
+	res, found := s.state[id]
+	s.mu.RUnlock()
+	data, ok := res.Data.(*fetcher.CalendarData)
+	if !found || !ok {
+		http.Error(w, "Calendar not loaded yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	if ext == ".json" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(newCalendarExport(data)); err != nil {
+			slog.Error("Failed to encode calendar export", "section", id, "error", err)
+		}
+		return
+	}
+
+	stamp := res.Status.LastFetch
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	var buf bytes.Buffer
+	if err := fetcher.EncodeICS(&buf, data, id, s.config.UI.Location(), stamp); err != nil {
+		slog.Error("Failed to encode calendar export", "section", id, "error", err)
+		http.Error(w, "Failed to encode calendar", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// calendarExportToken returns the export token of a calendar section and
+// whether the section exists.
+func (s *DashboardServer) calendarExportToken(id string) (string, bool) {
+	for _, sec := range s.config.Sections {
+		if sec.ID == id && sec.Type == "calendar" {
+			if sec.Calendar != nil {
+				return sec.Calendar.ExportToken, true
+			}
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// calendarExport is the JSON export of a calendar section. The sources are
+// listed without their errors, which may name upstream hosts and accounts.
+type calendarExport struct {
+	Source  string                         `json:"source"`
+	Events  []fetcher.CalendarEvent        `json:"events"`
+	Sources []fetcher.CalendarSourceStatus `json:"sources,omitempty"`
+}
+
+func newCalendarExport(data *fetcher.CalendarData) calendarExport {
+	sources := make([]fetcher.CalendarSourceStatus, len(data.Sources))
+	for i, src := range data.Sources {
+		src.Error = ""
+		sources[i] = src
+	}
+	return calendarExport{Source: data.Source, Events: data.AllEvents(), Sources: sources}
+}
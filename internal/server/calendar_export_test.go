@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bros_kiosk/internal/config"
+	"bros_kiosk/pkg/fetcher"
+)
+
+func TestCalendarExportHandler(t *testing.T) {
+	cfg := &config.Config{
+		Sections: []config.Section{
+			{ID: "family", Type: "calendar"},
+			{ID: "work", Type: "calendar", Calendar: &config.CalendarConfig{ExportToken: "s3cret"}},
+			{ID: "pending", Type: "calendar"},
+			{ID: "news", Type: "rss"},
+		},
+	}
+	srv := New(cfg)
+
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	data := &fetcher.CalendarData{Source: "family", Events: []fetcher.CalendarEvent{
+		{UID: "dinner", Summary: "Dinner", Start: start, End: start.Add(time.Hour)},
+	}, Sources: []fetcher.CalendarSourceStatus{
+		{Name: "School", State: fetcher.SourceError, Error: "Get \"https://jane:pw@school.example/cal.ics\": 401"},
+	}}
+	srv.mu.Lock()
+	srv.state["family"] = fetcher.Result{FetcherName: "family", Data: data, Status: fetcher.Status{IsHealthy: true, LastFetch: time.Now()}}
+	srv.state["work"] = fetcher.Result{FetcherName: "work", Data: data, Status: fetcher.Status{IsHealthy: true, LastFetch: time.Now()}}
+	srv.mu.Unlock()
+
+	tests := []struct {
+		name       string
+		path       string
+		auth       string
+		wantStatus int
+		wantType   string
+	}{
+		{name: "ICS", path: "/api/calendars/family.ics", wantStatus: http.StatusOK, wantType: "text/calendar"},
+		{name: "JSON", path: "/api/calendars/family.json", wantStatus: http.StatusOK, wantType: "application/json"},
+		{name: "UnknownFormat", path: "/api/calendars/family.xml", wantStatus: http.StatusNotFound},
+		{name: "UnknownSection", path: "/api/calendars/holidays.ics", wantStatus: http.StatusNotFound},
+		{name: "NotACalendar", path: "/api/calendars/news.ics", wantStatus: http.StatusNotFound},
+		{name: "NotLoaded", path: "/api/calendars/pending.ics", wantStatus: http.StatusServiceUnavailable},
+		{name: "MissingToken", path: "/api/calendars/work.ics", wantStatus: http.StatusUnauthorized},
+		{name: "WrongToken", path: "/api/calendars/work.ics?token=guess", wantStatus: http.StatusUnauthorized},
+		{name: "TokenParameter", path: "/api/calendars/work.ics?token=s3cret", wantStatus: http.StatusOK, wantType: "text/calendar"},
+		{name: "BearerToken", path: "/api/calendars/work.json", auth: "Bearer s3cret", wantStatus: http.StatusOK, wantType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rr := httptest.NewRecorder()
+			srv.server.Handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantType != "" && !strings.HasPrefix(rr.Header().Get("Content-Type"), tt.wantType) {
+				t.Errorf("Content-Type = %q, want %s", rr.Header().Get("Content-Type"), tt.wantType)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/calendars/family.ics", nil)
+	rr := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, req)
+	if body := rr.Body.String(); !strings.Contains(body, "UID:dinner") || !strings.Contains(body, "SUMMARY:Dinner") {
+		t.Errorf("Unexpected feed:\n%s", body)
+	}
+
+	req = httptest.NewRequest("GET", "/api/calendars/family.json", nil)
+	rr = httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, req)
+	var got fetcher.CalendarData
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(got.Events) != 1 || got.Events[0].Summary != "Dinner" {
+		t.Errorf("Unexpected events: %+v", got.Events)
+	}
+	// Source errors may name upstream hosts and are left out.
+	if len(got.Sources) != 1 || got.Sources[0].State != fetcher.SourceError || strings.Contains(rr.Body.String(), "school.example") {
+		t.Errorf("Unexpected sources: %s", rr.Body.String())
+	}
+}
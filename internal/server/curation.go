@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"bros_kiosk/internal/scanner"
+)
+
+type photoInfo struct {
+	ID       string     `json:"id"`
+	Path     string     `json:"path"`
+	Caption  string     `json:"caption,omitempty"`
+	Favorite bool       `json:"favorite"`
+	Hidden   bool       `json:"hidden"`
+	Similar  int        `json:"similar,omitempty"`
+	ShownAt  *time.Time `json:"shown_at,omitempty"`
+}
+
+func (s *DashboardServer) photoInfo(path string) photoInfo {
+	return photoInfo{
+		ID:       scanner.PhotoID(path),
+		Path:     path,
+		Caption:  s.scannerMgr.Caption(path),
+		Favorite: s.scannerMgr.IsFavorite(path),
+		Hidden:   s.scannerMgr.IsHidden(path),
+		Similar:  len(s.scannerMgr.Duplicates(path)),
+	}
+}
+
+func (s *DashboardServer) recentPhotos() []photoInfo {
+	recent := s.scannerMgr.Recent()
+	photos := make([]photoInfo, 0, len(recent))
+	for _, r := range recent {
+		info := s.photoInfo(r.Path)
+		shownAt := r.ShownAt
+		info.ShownAt = &shownAt
+		photos = append(photos, info)
+	}
+	return photos
+}
+
+// NextPhotoHandler hands out the next slideshow photo of the display that
+// asks, so every display shows each photo in turn.
+func (s *DashboardServer) NextPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	photo, ok := s.scannerMgr.NextFor(displayID(r))
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.photoInfo(photo))
+}
+
+// displayID identifies the display asking for a photo: the id the
+// dashboard script sends, or else the client's address.
+func displayID(r *http.Request) string {
+	if id := r.URL.Query().Get("display"); id != "" {
+		return id
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// SkipPhotoHandler asks connected displays to advance to the next photo.
+func (s *DashboardServer) SkipPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	s.skipPhoto()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *DashboardServer) skipPhoto() {
+	s.mu.Lock()
+	s.photoSkips++
+	s.mu.Unlock()
+}
+
+// RecentPhotosHandler lists recently shown photos with their curation state.
+func (s *DashboardServer) RecentPhotosHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"photos": s.recentPhotos(),
+	})
+}
+
+// PhotoActionHandler applies hide/unhide/favorite/unfavorite to a photo.
+func (s *DashboardServer) PhotoActionHandler(w http.ResponseWriter, r *http.Request) {
+	if s.curation == nil {
+		http.Error(w, "Photo curation not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	path, ok := s.scannerMgr.Lookup(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "Photo not found", http.StatusNotFound)
+		return
+	}
+
+	var err error
+	switch r.PathValue("action") {
+	case "hide":
+		err = s.curation.SetHidden(path, true)
+		if err == nil && s.scannerMgr.Current() == path {
+			s.skipPhoto()
+		}
+	case "unhide":
+		err = s.curation.SetHidden(path, false)
+	case "favorite":
+		err = s.curation.SetFavorite(path, true)
+	case "unfavorite":
+		err = s.curation.SetFavorite(path, false)
+	default:
+		http.Error(w, "Unknown action", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		slog.Error("Failed to save photo curation", "path", path, "error", err)
+		http.Error(w, "Failed to save curation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.photoInfo(path))
+}
+
+// CurateHandler renders a mobile-friendly page for curating recent photos.
+func (s *DashboardServer) CurateHandler(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Photos []photoInfo
+	}{
+		Photos: s.recentPhotos(),
+	}
+	if err := s.templates.ExecuteTemplate(w, "curate.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
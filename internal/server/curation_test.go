@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bros_kiosk/internal/config"
+	"bros_kiosk/internal/scanner"
+)
+
+func TestPhotoCuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("data "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(&config.Config{
+		Slideshow: config.SlideshowConfig{
+			Sources: []config.SourceConfig{{Type: "local", Path: tmpDir}},
+		},
+	})
+	curation, err := scanner.NewCuration(filepath.Join(tmpDir, "curation.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.curation = curation
+	srv.scannerMgr.SetCuration(curation)
+	if err := srv.scannerMgr.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/photos/next", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from next, got %d", rr.Code)
+	}
+	var shown photoInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &shown); err != nil {
+		t.Fatal(err)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/photos/"+shown.ID+"/hide", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from hide, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !curation.IsHidden(shown.Path) {
+		t.Error("Expected photo to be hidden")
+	}
+	if srv.photoSkips != 1 {
+		t.Errorf("Hiding the current photo should skip it, got %d skips", srv.photoSkips)
+	}
+	if photos := srv.scannerMgr.GetPhotos(); len(photos) != 1 || photos[0] == shown.Path {
+		t.Errorf("Expected hidden photo to be excluded, got %v", photos)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/photos/unknown/hide", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown photo, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/curate", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), shown.ID) {
+		t.Errorf("Expected curate page to list %s, got %d", shown.ID, rr.Code)
+	}
+}
+
+func TestSkipPhotoHandler(t *testing.T) {
+	srv := New(&config.Config{})
+
+	rr := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/photos/skip", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/updates", nil))
+	var resp struct {
+		PhotoSkips int `json:"photo_skips"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.PhotoSkips != 1 {
+		t.Errorf("Expected photo_skips 1, got %d", resp.PhotoSkips)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 func TestDashboardHandler(t *testing.T) {
 	cfg := &config.Config{
+		UI: config.UIConfig{Locale: "de-DE"},
 		Sections: []config.Section{
 			{ID: "weather", Type: "widget", Style: "default"},
 		},
@@ -39,6 +40,11 @@ func TestDashboardHandler(t *testing.T) {
 	if !strings.Contains(rr.Body.String(), "id=\"weather\"") {
 		t.Error("body does not contain expected section ID")
 	}
+
+	// The alert countdown is written with the words of the locale.
+	if !strings.Contains(rr.Body.String(), `countdown: {"now":"jetzt","in":"in %s","hour":"Std.","minute":"Min."}`) {
+		t.Error("body does not contain the countdown words of the locale")
+	}
 }
 
 func TestDashboardHandler_Error(t *testing.T) {
@@ -72,7 +72,7 @@ func (s *DashboardServer) ImageHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DashboardServer) collectDashboardData(targetWidth, targetHeight int) renderer.DashboardData {
-	bgImg := s.loadBackgroundImage(targetWidth, targetHeight)
+	bgImg, bgPath := s.loadBackgroundImage(targetWidth, targetHeight)
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -80,10 +80,16 @@ func (s *DashboardServer) collectDashboardData(targetWidth, targetHeight int) re
 	data := renderer.DashboardData{
 		Config:      s.config,
 		SectionData: make(map[string]interface{}),
-		Time:        time.Now(),
+		Time:        time.Now().In(s.config.UI.Location()),
+		Location:    s.config.UI.Location(),
 		Locale:      s.config.UI.Locale,
 		TimeFormat:  s.config.UI.TimeFormat,
 		Background:  bgImg,
+		Thumbnails:  make(map[string]image.Image),
+	}
+
+	if bgImg != nil {
+		data.BackgroundCaption = s.scannerMgr.Caption(bgPath)
 	}
 
 	for _, sec := range s.config.Sections {
@@ -109,11 +115,18 @@ func (s *DashboardServer) collectDashboardData(targetWidth, targetHeight int) re
 				if rssResult.Data != nil {
 					if rd, ok := rssResult.Data.(*fetcher.RSSData); ok {
 						for _, item := range rd.Items {
-							pubDate, _ := time.Parse(time.RFC1123Z, item.PubDate)
+							if item.Thumbnail != "" {
+								if img := s.cachedThumbnail(item.Thumbnail); img != nil {
+									data.Thumbnails[item.Thumbnail] = img
+								}
+							}
 							data.News = append(data.News, renderer.NewsItem{
-								Title:   item.Title,
-								Summary: item.Summary,
-								PubDate: pubDate,
+								Title:     item.Title,
+								Summary:   item.Summary,
+								Source:    item.Source,
+								PubDate:   item.PubDate,
+								Undated:   item.Undated,
+								Thumbnail: item.Thumbnail,
 							})
 						}
 					}
@@ -140,23 +153,27 @@ func (s *DashboardServer) collectDashboardData(targetWidth, targetHeight int) re
 		}
 	}
 
+	for _, alert := range s.alertsLocked(data.Time) {
+		data.Alerts = append(data.Alerts, renderer.Alert{
+			Summary:  alert.Summary,
+			Location: alert.Location,
+			Calendar: alert.Calendar,
+			Color:    alert.Color,
+			Start:    alert.Start,
+		})
+	}
+
 	return data
 }
 
-func (s *DashboardServer) loadBackgroundImage(targetWidth, targetHeight int) image.Image {
+func (s *DashboardServer) loadBackgroundImage(targetWidth, targetHeight int) (image.Image, string) {
 	if s.scannerMgr == nil {
-		return nil
+		return nil, ""
 	}
 
-	photos := s.scannerMgr.GetPhotos()
-	if len(photos) == 0 {
-		return nil
-	}
-
-	photoPath := photos[0]
-	if len(photos) > 1 {
-		idx := int(time.Now().Unix()/30) % len(photos)
-		photoPath = photos[idx]
+	photoPath, ok := s.backgroundPhoto(time.Now())
+	if !ok {
+		return nil, ""
 	}
 
 	if cachedPath, found := s.imageCache.Get(photoPath); found {
@@ -165,7 +182,7 @@ func (s *DashboardServer) loadBackgroundImage(targetWidth, targetHeight int) ima
 			defer f.Close()
 			img, _, err := image.Decode(f)
 			if err == nil {
-				return img
+				return img, photoPath
 			}
 		}
 	}
@@ -173,19 +190,49 @@ func (s *DashboardServer) loadBackgroundImage(targetWidth, targetHeight int) ima
 	srcFile, err := os.Open(photoPath)
 	if err != nil {
 		slog.Debug("Failed to open photo for background", "path", photoPath, "error", err)
-		return nil
+		return nil, ""
 	}
 	defer srcFile.Close()
 
 	resizedImg, err := images.Resize(srcFile, targetWidth, targetHeight)
 	if err != nil {
 		slog.Debug("Failed to resize background image", "error", err)
-		return nil
+		return nil, ""
 	}
 
 	s.imageCache.Put(photoPath, resizedImg)
 
-	return resizedImg
+	return resizedImg, photoPath
+}
+
+// imageRotation is how long the rendered image keeps its background photo.
+const imageRotation = 30 * time.Second
+
+// imageDisplay is the slideshow place of the rendered image, kept apart from
+// the HTML displays so neither skips photos of the other.
+const imageDisplay = "image"
+
+// backgroundPhoto returns the background of the rendered image. It advances
+// through the scanner manager like an HTML display does, once per
+// imageRotation and whenever displays are asked to skip, so favorites,
+// hidden photos and queued uploads apply to both.
+func (s *DashboardServer) backgroundPhoto(now time.Time) (string, bool) {
+	slot := now.UnixNano() / int64(imageRotation)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bg := &s.background
+	if bg.path != "" && bg.slot == slot && bg.skips == s.photoSkips && !s.scannerMgr.IsHidden(bg.path) {
+		return bg.path, true
+	}
+
+	photo, ok := s.scannerMgr.NextFor(imageDisplay)
+	if !ok {
+		return "", false
+	}
+	bg.path, bg.slot, bg.skips = photo, slot, s.photoSkips
+	return photo, true
 }
 
 func writeRawRGBA(w io.Writer, img image.Image) {
@@ -1,16 +1,20 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"image/png"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"bros_kiosk/internal/cache"
 	"bros_kiosk/internal/config"
 	"bros_kiosk/internal/renderer"
+	"bros_kiosk/internal/scanner"
 	"bros_kiosk/pkg/fetcher"
 )
 
@@ -119,7 +123,7 @@ func TestCollectDashboardData(t *testing.T) {
 				FetcherName: "news",
 				Data: &fetcher.RSSData{
 					Items: []fetcher.RSSItem{
-						{Title: "Test News", Summary: "Summary", PubDate: time.Now().Format(time.RFC1123Z)},
+						{Title: "Test News", Summary: "Summary", PubDate: time.Now()},
 					},
 				},
 			},
@@ -227,3 +231,55 @@ func TestImageHandler_JSON_Not_Supported(t *testing.T) {
 
 	_ = json.RawMessage{}
 }
+
+func TestBackgroundPhoto(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("data "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	srv := New(&config.Config{
+		Slideshow: config.SlideshowConfig{
+			Sources: []config.SourceConfig{{Type: "local", Path: tmpDir}},
+		},
+	})
+	curation, err := scanner.NewCuration(filepath.Join(tmpDir, "curation.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.curation = curation
+	srv.scannerMgr.SetCuration(curation)
+	if err := srv.scannerMgr.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	hidden := filepath.Join(tmpDir, "c.jpg")
+	if err := curation.SetHidden(hidden, true); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	first, ok := srv.backgroundPhoto(now)
+	if !ok {
+		t.Fatal("Expected a background photo")
+	}
+	if again, _ := srv.backgroundPhoto(now); again != first {
+		t.Errorf("Expected the same photo within a rotation, got %s and %s", first, again)
+	}
+	if recent := srv.scannerMgr.Recent(); len(recent) != 1 || recent[0].Path != first {
+		t.Errorf("Expected the photo to be recorded as shown, got %+v", recent)
+	}
+
+	srv.skipPhoto()
+	second, _ := srv.backgroundPhoto(now)
+	if second == first {
+		t.Errorf("Expected a skip to advance the photo, got %s again", second)
+	}
+
+	for i := 1; i <= 10; i++ {
+		if photo, _ := srv.backgroundPhoto(now.Add(time.Duration(i) * imageRotation)); photo == hidden {
+			t.Fatal("Hidden photo used as background")
+		}
+	}
+}
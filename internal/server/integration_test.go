@@ -35,6 +35,7 @@ VERSION:2.0
 BEGIN:VEVENT
 SUMMARY:Test Event
 DTSTART:20251225T100000Z
+RRULE:FREQ=DAILY
 END:VEVENT
 END:VCALENDAR`))
 	}))
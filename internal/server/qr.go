@@ -0,0 +1,66 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"bros_kiosk/pkg/fetcher"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const qrCodeSize = 256
+
+// QRHandler serves a QR code as PNG. ?section=<id> encodes the payload of a
+// "qr" section; ?url=<link> encodes the link of a news item currently on the
+// dashboard. Other payloads are rejected so the endpoint cannot be used as a
+// general-purpose encoder.
+func (s *DashboardServer) QRHandler(w http.ResponseWriter, r *http.Request) {
+	payload := ""
+	if id := r.URL.Query().Get("section"); id != "" {
+		payload = s.qrPayload(id)
+	} else if url := r.URL.Query().Get("url"); url != "" && s.knownLink(url) {
+		payload = url
+	}
+	if payload == "" {
+		http.Error(w, "QR code not found", http.StatusNotFound)
+		return
+	}
+
+	png, err := qrcode.Encode(payload, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		slog.Error("Failed to encode QR code", "error", err)
+		http.Error(w, "Failed to encode QR code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(png)
+}
+
+func (s *DashboardServer) qrPayload(id string) string {
+	for _, sec := range s.config.Sections {
+		if sec.ID == id && sec.Type == "qr" && sec.QR != nil {
+			return sec.QR.Payload()
+		}
+	}
+	return ""
+}
+
+func (s *DashboardServer) knownLink(url string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, res := range s.state {
+		rd, ok := res.Data.(*fetcher.RSSData)
+		if !ok {
+			continue
+		}
+		for _, item := range rd.Items {
+			if item.Link == url {
+				return true
+			}
+		}
+	}
+	return false
+}
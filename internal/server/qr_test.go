@@ -0,0 +1,60 @@
+package server
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"bros_kiosk/internal/config"
+	"bros_kiosk/pkg/fetcher"
+)
+
+func TestQRHandler(t *testing.T) {
+	srv := &DashboardServer{
+		config: &config.Config{
+			Sections: []config.Section{
+				{ID: "wifi", Type: "qr", QR: &config.QRConfig{WiFi: &config.WiFiConfig{SSID: "Guests", Password: "secret"}}},
+				{ID: "news", Type: "rss"},
+			},
+		},
+		state: map[string]fetcher.Result{
+			"news": {Data: &fetcher.RSSData{Items: []fetcher.RSSItem{{Title: "A", Link: "https://example.com/a"}}}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"qr section", "section=wifi", http.StatusOK},
+		{"known news link", "url=" + url.QueryEscape("https://example.com/a"), http.StatusOK},
+		{"non-qr section", "section=news", http.StatusNotFound},
+		{"unknown section", "section=missing", http.StatusNotFound},
+		{"unknown link", "url=" + url.QueryEscape("https://example.com/b"), http.StatusNotFound},
+		{"no payload", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.QRHandler(rr, httptest.NewRequest("GET", "/api/qr?"+tt.query, nil))
+
+			if rr.Code != tt.want {
+				t.Fatalf("Expected %d, got %d", tt.want, rr.Code)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			img, err := png.Decode(rr.Body)
+			if err != nil {
+				t.Fatalf("Failed to decode QR code: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != qrCodeSize || b.Dy() != qrCodeSize {
+				t.Errorf("Expected %dx%d QR code, got %v", qrCodeSize, qrCodeSize, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"bros_kiosk/pkg/fetcher"
+)
+
+// AlertsHandler lists the calendar reminders that are due and not
+// dismissed, soonest event first.
+func (s *DashboardServer) AlertsHandler(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	alerts := s.alertsLocked(time.Now())
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(map[string]interface{}{"alerts": alerts})
+}
+
+// DismissAlertHandler hides an alert on every display until its event has
+// started.
+func (s *DashboardServer) DismissAlertHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var alert *fetcher.Alert
+	for _, a := range s.alertsLocked(now) {
+		if a.ID == id {
+			alert = &a
+			break
+		}
+	}
+	if alert == nil {
+		http.Error(w, "Alert not found", http.StatusNotFound)
+		return
+	}
+
+	for dismissedID, start := range s.dismissed {
+		if !start.After(now) {
+			delete(s.dismissed, dismissedID)
+		}
+	}
+	s.dismissed[id] = alert.Start
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "dismissed", "id": id})
+}
+
+// alertsLocked gathers the active alerts of all calendar sections. The
+// caller must hold s.mu.
+func (s *DashboardServer) alertsLocked(now time.Time) []fetcher.Alert {
+	alerts := []fetcher.Alert{}
+	seen := make(map[string]bool)
+	for _, sec := range s.config.Sections {
+		if sec.Type != "calendar" {
+			continue
+		}
+		data, ok := s.state[sec.ID].Data.(*fetcher.CalendarData)
+		if !ok {
+			continue
+		}
+		for _, a := range data.Alerts(now) {
+			if _, dismissed := s.dismissed[a.ID]; dismissed || seen[a.ID] {
+				continue
+			}
+			seen[a.ID] = true
+			alerts = append(alerts, a)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Start.Before(alerts[j].Start)
+	})
+	return alerts
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bros_kiosk/internal/config"
+	"bros_kiosk/pkg/fetcher"
+)
+
+func TestAlertsHandlers(t *testing.T) {
+	cfg := &config.Config{
+		Sections: []config.Section{
+			{ID: "family", Type: "calendar"},
+			{ID: "work", Type: "calendar"},
+		},
+	}
+	srv := New(cfg)
+
+	now := time.Now()
+	soon, later := now.Add(10*time.Minute), now.Add(2*time.Hour)
+	remindSoon, remindLater := soon.Add(-15*time.Minute), later.Add(-15*time.Minute)
+	srv.mu.Lock()
+	srv.state["family"] = fetcher.Result{FetcherName: "family", Data: &fetcher.CalendarData{Events: []fetcher.CalendarEvent{
+		{UID: "school", Summary: "School run", Start: soon, End: soon.Add(30 * time.Minute), RemindAt: &remindSoon},
+		{UID: "dinner", Summary: "Dinner", Start: later, End: later.Add(time.Hour), RemindAt: &remindLater},
+	}}}
+	srv.state["work"] = fetcher.Result{FetcherName: "work", Data: &fetcher.CalendarData{Events: []fetcher.CalendarEvent{
+		{UID: "standup", Summary: "Standup", Start: soon.Add(time.Minute), End: soon.Add(16 * time.Minute), RemindAt: &remindSoon},
+	}}}
+	srv.mu.Unlock()
+
+	list := func() []fetcher.Alert {
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/alerts", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Status = %d, want %d", rr.Code, http.StatusOK)
+		}
+		var resp struct {
+			Alerts []fetcher.Alert `json:"alerts"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp.Alerts
+	}
+	dismiss := func(id string) int {
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/alerts/"+id+"/dismiss", nil))
+		return rr.Code
+	}
+
+	alerts := list()
+	if len(alerts) != 2 || alerts[0].Summary != "School run" || alerts[1].Summary != "Standup" {
+		t.Fatalf("Unexpected alerts: %+v", alerts)
+	}
+
+	if code := dismiss(alerts[0].ID); code != http.StatusOK {
+		t.Fatalf("Dismiss status = %d, want %d", code, http.StatusOK)
+	}
+	if alerts = list(); len(alerts) != 1 || alerts[0].Summary != "Standup" {
+		t.Errorf("Expected the dismissed alert to be hidden, got %+v", alerts)
+	}
+
+	if code := dismiss("unknown"); code != http.StatusNotFound {
+		t.Errorf("Dismiss unknown status = %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"io/fs"
@@ -10,6 +12,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -21,6 +25,7 @@ import (
 	"bros_kiosk/internal/renderer"
 	"bros_kiosk/internal/scanner"
 	"bros_kiosk/pkg/fetcher"
+	"bros_kiosk/pkg/textutil"
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -31,6 +36,10 @@ func init() {
 	mime.AddExtensionType(".js", "application/javascript")
 }
 
+// defaultDedupeDistance is the dHash Hamming distance under which two photos
+// count as the same shot when dedupe.max_distance is not set.
+const defaultDedupeDistance = 6
+
 type DashboardServer struct {
 	server    *http.Server
 	config    *config.Config
@@ -42,8 +51,21 @@ type DashboardServer struct {
 	state         map[string]fetcher.Result
 	mu            sync.RWMutex
 	imageCache    *images.DiskCache
+	thumbCache    *images.DiskCache
+	thumbClient   *http.Client
 	scannerMgr    *scanner.Manager
+	curation      *scanner.Curation
+	photoSkips    int
 	imageRenderer renderer.Renderer
+	// dismissed holds the IDs of dismissed alerts until their event starts.
+	dismissed map[string]time.Time
+	// background is the photo behind the rendered image, with the rotation
+	// slot and skip count it was picked at.
+	background struct {
+		path  string
+		slot  int64
+		skips int
+	}
 }
 
 func New(cfg *config.Config) *DashboardServer {
@@ -56,6 +78,11 @@ func New(cfg *config.Config) *DashboardServer {
 		panic(err)
 	}
 
+	thumbCache, err := images.NewDiskCache(filepath.Join("./kiosk_cache", "thumbs"))
+	if err != nil {
+		panic(err)
+	}
+
 	var scanners []scanner.Scanner
 	for _, src := range cfg.Slideshow.Sources {
 		if src.Type == "local" {
@@ -68,9 +95,37 @@ func New(cfg *config.Config) *DashboardServer {
 			}
 			client := s3.NewFromConfig(awsCfg)
 			scanners = append(scanners, scanner.NewS3Scanner(client, src.Bucket, src.Prefix))
+		} else if src.Type == "feed" {
+			dir := src.Path
+			if dir == "" {
+				dir = feedCacheDir(src.URL)
+			}
+			scanners = append(scanners, scanner.NewFeedScanner(src.URL, dir, src.Limit))
 		}
 	}
 	scanMgr := scanner.NewManager(scanners...)
+	scanMgr.SetShuffle(cfg.Slideshow.Shuffle)
+
+	curation, err := scanner.NewCuration(filepath.Join("./kiosk_cache", "curation.json"))
+	if err != nil {
+		slog.Error("Failed to load photo curation, hide/favorite disabled", "error", err)
+		curation = nil
+	} else {
+		scanMgr.SetCuration(curation)
+	}
+
+	if dd := cfg.Slideshow.Dedupe; dd != nil {
+		hashes, err := images.NewHashStore(filepath.Join("./kiosk_cache", "phash.json"))
+		if err != nil {
+			slog.Error("Failed to load photo hashes, duplicate detection disabled", "error", err)
+		} else {
+			maxDistance := defaultDedupeDistance
+			if dd.MaxDistance != nil {
+				maxDistance = *dd.MaxDistance
+			}
+			scanMgr.SetDeduper(scanner.NewDeduper(hashes, maxDistance))
+		}
+	}
 
 	ggRenderer, err := renderer.NewGGRenderer()
 	if err != nil {
@@ -96,8 +151,12 @@ func New(cfg *config.Config) *DashboardServer {
 		manager:       fetcher.NewManager(),
 		state:         make(map[string]fetcher.Result),
 		imageCache:    imgCache,
+		thumbCache:    thumbCache,
+		thumbClient:   &http.Client{Timeout: 10 * time.Second},
 		scannerMgr:    scanMgr,
+		curation:      curation,
 		imageRenderer: imageRenderer,
+		dismissed:     make(map[string]time.Time),
 	}
 
 	go func() {
@@ -127,22 +186,62 @@ func New(cfg *config.Config) *DashboardServer {
 			}
 		case "rss":
 			if sec.RSS != nil {
-				rf := fetcher.NewRSSFetcher(sec.ID, sec.RSS.URL)
-				if namer, ok := interface{}(rf).(interface{ SetName(string) }); ok {
-					namer.SetName(sec.ID)
+				feeds := sec.RSS.Feeds
+				if sec.RSS.URL != "" {
+					feeds = append([]config.RSSFeed{{URL: sec.RSS.URL}}, feeds...)
+				}
+
+				sectionFilter := newFeedFilter(sec.ID, sec.RSS.Filter)
+				fetchers := make([]*fetcher.RSSFetcher, 0, len(feeds))
+				for _, feed := range feeds {
+					feedFilter := newFeedFilter(sec.ID, feed.Filter)
+					rf := fetcher.NewRSSFetcher(sec.ID, feed.URL)
+					rf.SetSource(feed.Label, feed.Weight)
+					rf.SetSummaryLength(sec.RSS.SummaryLength)
+					rf.SetFilters(sectionFilter, feedFilter)
+					rf.SetThumbnails(sec.RSS.ShowThumbnails())
+					fetchers = append(fetchers, rf)
+				}
+
+				if len(fetchers) > 0 {
+					news := fetcher.NewNewsAggregator(sec.ID, fetchers, sec.RSS.MaxItems)
+					srv.manager.RegisterWithBackoff(news, interval, 5*time.Second, 1*time.Hour)
 				}
-				srv.manager.RegisterWithBackoff(rf, interval, 5*time.Second, 1*time.Hour)
 			}
 		case "calendar":
 			if len(sec.Calendars) > 0 {
+				opts := fetcher.CalendarOptions{Location: cfg.UI.Location(), Locale: cfg.UI.Locale}
+				if sec.Calendar != nil {
+					opts.DaysAhead = sec.Calendar.DaysAhead
+					opts.MaxEvents = sec.Calendar.MaxEvents
+					opts.HidePast = sec.Calendar.HidePast
+					opts.HideAllDay = sec.Calendar.HideAllDay
+				}
+
 				fetchers := make([]fetcher.Fetcher, 0, len(sec.Calendars))
 				for _, cal := range sec.Calendars {
 					var f fetcher.Fetcher
 					switch cal.Type {
 					case "ical":
-						f = fetcher.NewICalFetcher(cal.Name, cal.URL)
+						icf := fetcher.NewICalFetcher(cal.Name, cal.URL)
+						icf.SetLocation(opts.Location)
+						icf.SetDaysAhead(opts.DaysAhead)
+						icf.SetColor(cal.Color)
+						icf.SetAuth(cal.Username, cal.Password)
+						icf.SetToken(cal.Token)
+						icf.SetHeaders(cal.Headers)
+						icf.SetRules(newEventRules(sec.ID, cal))
+						icf.SetReminders(newReminderRule(cal))
+						f = icf
 					case "caldav":
-						f = fetcher.NewCalDAVFetcher(cal.Name, cal.URL, cal.Username, cal.Password)
+						cdf := fetcher.NewCalDAVFetcher(cal.Name, cal.URL, cal.Username, cal.Password)
+						cdf.SetLocation(opts.Location)
+						cdf.SetDaysAhead(opts.DaysAhead)
+						cdf.SetColor(cal.Color)
+						cdf.SetRules(newEventRules(sec.ID, cal))
+						cdf.SetCalendars(cal.Calendars)
+						cdf.SetReminders(newReminderRule(cal))
+						f = cdf
 					}
 					if f != nil {
 						fetchers = append(fetchers, f)
@@ -151,6 +250,78 @@ func New(cfg *config.Config) *DashboardServer {
 
 				if len(fetchers) > 0 {
 					aggregator := fetcher.NewCalendarAggregator(sec.ID, fetchers)
+					aggregator.SetOptions(opts)
+					if sec.Calendar != nil && sec.Calendar.SourceTimeout != "" {
+						if d, err := time.ParseDuration(sec.Calendar.SourceTimeout); err == nil {
+							aggregator.SetSourceTimeout(d)
+						}
+					}
+					srv.manager.RegisterWithBackoff(aggregator, interval, 10*time.Second, 1*time.Hour)
+				}
+			}
+		case "tasks":
+			if len(sec.Calendars) > 0 {
+				opts := fetcher.TaskOptions{Location: cfg.UI.Location(), Locale: cfg.UI.Locale}
+				if sec.Tasks != nil {
+					opts.MaxItems = sec.Tasks.MaxItems
+					if sec.Tasks.CompletedGrace != "" {
+						if d, err := time.ParseDuration(sec.Tasks.CompletedGrace); err == nil {
+							opts.CompletedGrace = d
+						}
+					}
+				}
+
+				fetchers := make([]fetcher.Fetcher, 0, len(sec.Calendars))
+				for _, cal := range sec.Calendars {
+					var f fetcher.Fetcher
+					switch cal.Type {
+					case "ical":
+						itf := fetcher.NewICalTaskFetcher(cal.Name, cal.URL)
+						itf.SetLocation(opts.Location)
+						itf.SetColor(cal.Color)
+						itf.SetAuth(cal.Username, cal.Password)
+						itf.SetToken(cal.Token)
+						itf.SetHeaders(cal.Headers)
+						f = itf
+					case "caldav":
+						ctf := fetcher.NewCalDAVTaskFetcher(cal.Name, cal.URL, cal.Username, cal.Password)
+						ctf.SetLocation(opts.Location)
+						ctf.SetColor(cal.Color)
+						ctf.SetCalendars(cal.Calendars)
+						f = ctf
+					}
+					if f != nil {
+						fetchers = append(fetchers, f)
+					}
+				}
+
+				if len(fetchers) > 0 {
+					aggregator := fetcher.NewTaskAggregator(sec.ID, fetchers)
+					aggregator.SetOptions(opts)
+					srv.manager.RegisterWithBackoff(aggregator, interval, 10*time.Second, 1*time.Hour)
+				}
+			}
+		case "birthdays":
+			if len(sec.Calendars) > 0 {
+				opts := fetcher.BirthdayOptions{Location: cfg.UI.Location(), Locale: cfg.UI.Locale}
+				if sec.Birthdays != nil {
+					opts.MaxItems = sec.Birthdays.MaxItems
+					opts.DaysAhead = sec.Birthdays.DaysAhead
+				}
+
+				fetchers := make([]fetcher.Fetcher, 0, len(sec.Calendars))
+				for _, book := range sec.Calendars {
+					switch book.Type {
+					case "carddav":
+						fetchers = append(fetchers, fetcher.NewCardDAVFetcher(book.Name, book.URL, book.Username, book.Password))
+					case "vcf":
+						fetchers = append(fetchers, fetcher.NewVCardFileFetcher(book.Name, book.URL))
+					}
+				}
+
+				if len(fetchers) > 0 {
+					aggregator := fetcher.NewBirthdayAggregator(sec.ID, fetchers)
+					aggregator.SetOptions(opts)
 					srv.manager.RegisterWithBackoff(aggregator, interval, 10*time.Second, 1*time.Hour)
 				}
 			}
@@ -162,6 +333,18 @@ func New(cfg *config.Config) *DashboardServer {
 	mux.HandleFunc("/dashboard/image", srv.ImageHandler)
 	mux.HandleFunc("/api/updates", srv.UpdateHandler)
 	mux.HandleFunc("/api/photos", srv.PhotosListHandler)
+	mux.HandleFunc("GET /api/photos/next", srv.NextPhotoHandler)
+	mux.HandleFunc("GET /api/photos/recent", srv.RecentPhotosHandler)
+	mux.HandleFunc("POST /api/photos/skip", srv.SkipPhotoHandler)
+	mux.HandleFunc("POST /api/photos/{id}/{action}", srv.PhotoActionHandler)
+	mux.HandleFunc("POST /api/photos/upload", srv.UploadHandler)
+	mux.HandleFunc("/curate", srv.CurateHandler)
+	mux.HandleFunc("/upload", srv.UploadPageHandler)
+	mux.HandleFunc("GET /api/thumbnail", srv.ThumbnailHandler)
+	mux.HandleFunc("GET /api/qr", srv.QRHandler)
+	mux.HandleFunc("GET /api/calendars/{file}", srv.CalendarExportHandler)
+	mux.HandleFunc("GET /api/alerts", srv.AlertsHandler)
+	mux.HandleFunc("POST /api/alerts/{id}/dismiss", srv.DismissAlertHandler)
 	mux.HandleFunc("/assets/photos/", srv.AssetHandler)
 
 	staticFS, err := fs.Sub(assets.FS, "static")
@@ -207,10 +390,12 @@ func (s *DashboardServer) DashboardHandler(w http.ResponseWriter, r *http.Reques
 		Config    *config.Config
 		Slideshow config.SlideshowConfig
 		Layout    interface{}
+		Countdown textutil.CountdownWords
 	}{
 		Config:    s.config,
 		Slideshow: s.config.Slideshow,
 		Layout:    layout,
+		Countdown: textutil.Countdown(s.config.UI.Locale),
 	}
 	err := s.templates.ExecuteTemplate(w, "dashboard.html", data)
 	if err != nil {
@@ -228,6 +413,8 @@ func (s *DashboardServer) Start() error {
 
 	go s.manager.Start(ctx)
 
+	go s.rescanPhotos(ctx)
+
 	go func() {
 		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			slog.Error("Server error", "error", err)
@@ -252,8 +439,98 @@ func (s *DashboardServer) listenForUpdates(ctx context.Context) {
 			s.mu.Lock()
 			s.state[result.FetcherName] = result
 			s.mu.Unlock()
+			go s.prefetchThumbnails(ctx, result)
+		}
+	}
+}
+
+// rescanPhotos periodically refreshes the slideshow so new files and feed
+// items show up without a restart.
+func (s *DashboardServer) rescanPhotos(ctx context.Context) {
+	interval := time.Hour
+	if s.config.Slideshow.RescanInterval != "" {
+		if d, err := time.ParseDuration(s.config.Slideshow.RescanInterval); err == nil && d > 0 {
+			interval = d
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.scannerMgr.Scan(ctx); err != nil {
+				slog.Error("Photo rescan failed", "error", err)
+			}
+		}
+	}
+}
+
+// newFeedFilter builds the filter for a news section or feed. The config has
+// been validated, so errors only disable the filter.
+func newFeedFilter(section string, fc *config.FilterConfig) *fetcher.FeedFilter {
+	if fc == nil {
+		return nil
+	}
+
+	var maxAge time.Duration
+	if fc.MaxAge != "" {
+		maxAge, _ = time.ParseDuration(fc.MaxAge)
+	}
+
+	filter, err := fetcher.NewFeedFilter(fc.Include, fc.Exclude, fc.IncludeRegex, fc.ExcludeRegex, maxAge)
+	if err != nil {
+		slog.Error("Invalid news filter, filtering disabled", "section", section, "error", err)
+		return nil
+	}
+	return filter
+}
+
+// newEventRules builds the event rules of a calendar. The config has been
+// validated, so errors only disable the rules.
+func newEventRules(section string, cal config.CalendarSource) *fetcher.EventRules {
+	rewrites := make([]fetcher.TitleRewrite, 0, len(cal.Rewrites))
+	for _, rw := range cal.Rewrites {
+		rewrites = append(rewrites, fetcher.TitleRewrite{Match: rw.Match, Replace: rw.Replace})
+	}
+
+	var attendees []string
+	if cal.Email != "" {
+		attendees = append(attendees, cal.Email)
+	}
+	if strings.Contains(cal.Username, "@") {
+		attendees = append(attendees, cal.Username)
+	}
+
+	rules, err := fetcher.NewEventRules(cal.Privacy, rewrites, attendees)
+	if err != nil {
+		slog.Error("Invalid calendar rules, rules disabled", "section", section, "calendar", cal.Name, "error", err)
+		return nil
+	}
+	return rules
+}
+
+// newReminderRule returns the calendar's reminder rule, or nil when it has
+// none.
+func newReminderRule(cal config.CalendarSource) *fetcher.ReminderRule {
+	if cal.Reminders == nil {
+		return nil
+	}
+	rule := &fetcher.ReminderRule{Alarms: cal.Reminders.Alarms}
+	if cal.Reminders.Before != "" {
+		if d, err := time.ParseDuration(cal.Reminders.Before); err == nil {
+			rule.Before = d
 		}
 	}
+	return rule
+}
+
+func feedCacheDir(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join("./kiosk_cache", "feeds", hex.EncodeToString(hash[:6]))
 }
 
 func (s *DashboardServer) Notify(sig os.Signal) {
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"bros_kiosk/internal/images"
+	"bros_kiosk/pkg/fetcher"
+)
+
+const (
+	thumbnailSize     = 160
+	maxThumbnailBytes = 5 << 20
+)
+
+// ThumbnailHandler serves a news thumbnail, downloading and shrinking it on
+// first use. Only URLs of items currently on the dashboard are proxied.
+func (s *DashboardServer) ThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	url := r.URL.Query().Get("url")
+	if url == "" || !s.knownThumbnail(url) {
+		http.Error(w, "Thumbnail not found", http.StatusNotFound)
+		return
+	}
+
+	path, err := s.fetchThumbnail(r.Context(), url)
+	if err != nil {
+		slog.Debug("Failed to fetch thumbnail", "url", url, "error", err)
+		http.Error(w, "Failed to fetch thumbnail", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFile(w, r, path)
+}
+
+func (s *DashboardServer) knownThumbnail(url string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, res := range s.state {
+		rd, ok := res.Data.(*fetcher.RSSData)
+		if !ok {
+			continue
+		}
+		for _, item := range rd.Items {
+			if item.Thumbnail == url {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fetchThumbnail returns the cached thumbnail file for url, downloading it if
+// needed.
+func (s *DashboardServer) fetchThumbnail(ctx context.Context, url string) (string, error) {
+	if path, ok := s.thumbCache.Get(url); ok {
+		return path, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.thumbClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	img, err := images.Resize(io.LimitReader(resp.Body, maxThumbnailBytes), thumbnailSize, thumbnailSize)
+	if err != nil {
+		return "", err
+	}
+	return s.thumbCache.Put(url, img)
+}
+
+// prefetchThumbnails downloads the thumbnails of a news result so the image
+// renderer, which only reads the cache, can draw them.
+func (s *DashboardServer) prefetchThumbnails(ctx context.Context, result fetcher.Result) {
+	rd, ok := result.Data.(*fetcher.RSSData)
+	if !ok {
+		return
+	}
+	for _, item := range rd.Items {
+		if item.Thumbnail == "" {
+			continue
+		}
+		if _, err := s.fetchThumbnail(ctx, item.Thumbnail); err != nil {
+			slog.Debug("Failed to prefetch thumbnail", "url", item.Thumbnail, "error", err)
+		}
+	}
+}
+
+// cachedThumbnail decodes a thumbnail from the cache without downloading it.
+func (s *DashboardServer) cachedThumbnail(url string) image.Image {
+	if s.thumbCache == nil {
+		return nil
+	}
+	path, ok := s.thumbCache.Get(url)
+	if !ok {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil
+	}
+	return img
+}
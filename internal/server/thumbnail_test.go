@@ -0,0 +1,69 @@
+package server
+
+import (
+	"image"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"bros_kiosk/internal/images"
+	"bros_kiosk/pkg/fetcher"
+)
+
+func TestThumbnailHandler(t *testing.T) {
+	requests := 0
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "image/jpeg")
+		jpeg.Encode(w, image.NewRGBA(image.Rect(0, 0, 800, 600)), nil)
+	}))
+	defer origin.Close()
+
+	thumbCache, err := images.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbURL := origin.URL + "/thumb.jpg"
+	srv := &DashboardServer{
+		state: map[string]fetcher.Result{
+			"news": {Data: &fetcher.RSSData{Items: []fetcher.RSSItem{{Title: "A", Thumbnail: thumbURL}}}},
+		},
+		thumbCache:  thumbCache,
+		thumbClient: origin.Client(),
+	}
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/api/thumbnail?url="+url.QueryEscape(thumbURL), nil)
+		rr := httptest.NewRecorder()
+		srv.ThumbnailHandler(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rr.Code)
+		}
+		img, _, err := image.Decode(rr.Body)
+		if err != nil {
+			t.Fatalf("Failed to decode thumbnail: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() > thumbnailSize || b.Dy() > thumbnailSize {
+			t.Errorf("Thumbnail not resized: %v", b)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single origin request thanks to the cache, got %d", requests)
+	}
+
+	if img := srv.cachedThumbnail(thumbURL); img == nil {
+		t.Error("Expected thumbnail in cache for the image renderer")
+	}
+
+	// Unknown URLs are not proxied.
+	req := httptest.NewRequest("GET", "/api/thumbnail?url="+url.QueryEscape(origin.URL+"/other.jpg"), nil)
+	rr := httptest.NewRecorder()
+	srv.ThumbnailHandler(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown thumbnail, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"bros_kiosk/internal/scanner"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp"
+)
+
+const defaultUploadMaxSizeMB = 20
+
+// maxUploadPhotos is how many photos of the maximum size one request may
+// carry; each photo is checked against the maximum size on its own.
+const maxUploadPhotos = 20
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// UploadHandler accepts one or more images in the "photo" multipart field and
+// stores them in the configured upload directory. A bearer token or token
+// parameter is checked before the body is read; browser forms send a token
+// field ahead of their photos instead, and no photo is read before it has
+// matched. A request is stored completely or not at all.
+func (s *DashboardServer) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config.Slideshow.Upload
+	if cfg == nil {
+		http.Error(w, "Uploads are not enabled", http.StatusNotFound)
+		return
+	}
+
+	authorized := false
+	if token := requestHeaderToken(r); token != "" {
+		if !uploadAuthorized(token, cfg.Token) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		authorized = true
+	}
+
+	maxSize := int64(cfg.MaxSizeMB)
+	if maxSize == 0 {
+		maxSize = defaultUploadMaxSizeMB
+	}
+	maxSize <<= 20
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadPhotos*maxSize+1<<20)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Expected a multipart upload", http.StatusBadRequest)
+		return
+	}
+
+	playNext := cfg.PlayNext
+	var saved []string
+	fail := func(msg string, code int) {
+		for _, path := range saved {
+			os.Remove(path)
+		}
+		http.Error(w, msg, code)
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fail("Upload too large or malformed", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		switch part.FormName() {
+		case "token":
+			if !authorized {
+				if !uploadAuthorized(readField(part), cfg.Token) {
+					fail("Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				authorized = true
+			}
+		case "play_next":
+			if v := readField(part); v == "on" || v == "true" {
+				playNext = true
+			}
+		case "photo":
+			if !authorized {
+				fail("Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+				fail("Upload directory unavailable", http.StatusInternalServerError)
+				return
+			}
+			path, err := s.saveUpload(part.FileName(), part, cfg.Dir, cfg.StripEXIF, maxSize)
+			if err != nil {
+				slog.Warn("Rejected photo upload", "file", part.FileName(), "error", err)
+				fail(fmt.Sprintf("%s: %v", part.FileName(), err), http.StatusBadRequest)
+				return
+			}
+			saved = append(saved, path)
+		}
+		part.Close()
+	}
+
+	if !authorized {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if len(saved) == 0 {
+		http.Error(w, "No photo provided", http.StatusBadRequest)
+		return
+	}
+
+	uploaded := make([]photoInfo, 0, len(saved))
+	for _, path := range saved {
+		s.scannerMgr.Add(path)
+		if playNext {
+			s.scannerMgr.Enqueue(path)
+		}
+		uploaded = append(uploaded, s.photoInfo(path))
+		slog.Info("Photo uploaded", "path", path)
+	}
+
+	if playNext {
+		s.skipPhoto()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"uploaded": uploaded,
+	})
+}
+
+// maxFieldSize caps the text fields read from an upload form.
+const maxFieldSize = 1 << 10
+
+// readField reads a text field of a multipart form.
+func readField(part *multipart.Part) string {
+	data, _ := io.ReadAll(io.LimitReader(part, maxFieldSize))
+	return strings.TrimSpace(string(data))
+}
+
+// UploadPageHandler renders the upload form.
+func (s *DashboardServer) UploadPageHandler(w http.ResponseWriter, r *http.Request) {
+	if s.config.Slideshow.Upload == nil {
+		http.Error(w, "Uploads are not enabled", http.StatusNotFound)
+		return
+	}
+
+	data := struct {
+		Token string
+	}{
+		Token: r.URL.Query().Get("token"),
+	}
+	if err := s.templates.ExecuteTemplate(w, "upload.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func uploadAuthorized(given, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// requestToken returns the bearer token of a request, or else its token
+// parameter.
+func requestToken(r *http.Request) string {
+	if token := requestHeaderToken(r); token != "" {
+		return token
+	}
+	return r.FormValue("token")
+}
+
+// requestHeaderToken returns the bearer token of a request, or else the
+// token parameter of its URL, without reading the body.
+func requestHeaderToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+func (s *DashboardServer) saveUpload(filename string, f io.Reader, dir string, stripEXIF bool, maxSize int64) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("file exceeds %d MB", maxSize>>20)
+	}
+
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("not a supported image")
+	}
+
+	ext := "." + format
+	if format == "jpeg" {
+		ext = ".jpg"
+	}
+
+	if stripEXIF && format != "gif" {
+		img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode image: %w", err)
+		}
+
+		encFormat := imaging.JPEG
+		if format == "png" {
+			encFormat = imaging.PNG
+		} else {
+			ext = ".jpg"
+		}
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, img, encFormat, imaging.JPEGQuality(95)); err != nil {
+			return "", fmt.Errorf("failed to re-encode image: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	base = strings.Trim(unsafeFileChars.ReplaceAllString(base, "_"), "._")
+	if base == "" {
+		base = "photo"
+	}
+	name := time.Now().Format("20060102-150405") + "-" + base + ext
+	path := filepath.Join(dir, name)
+
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		path = filepath.Join(dir, time.Now().Format("20060102-150405")+"-"+scanner.PhotoID(string(data))+ext)
+		out, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := out.Write(data); err != nil {
+		out.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"bros_kiosk/internal/config"
+)
+
+func uploadRequest(t *testing.T, token string, files map[string][]byte, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	// Fields go first, as browsers send them for the upload form.
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	for name, data := range files {
+		fw, err := mw.CreateFormFile("photo", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(data)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/photos/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestUploadHandler(t *testing.T) {
+	photosDir := t.TempDir()
+	inbox := filepath.Join(photosDir, "inbox")
+
+	srv := New(&config.Config{
+		Slideshow: config.SlideshowConfig{
+			Sources: []config.SourceConfig{{Type: "local", Path: photosDir}},
+			Upload:  &config.UploadConfig{Token: "secret", Dir: inbox, MaxSizeMB: 1, StripEXIF: true},
+		},
+	})
+
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, uploadRequest(t, "wrong", map[string][]byte{"a.jpg": jpg.Bytes()}, nil))
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", rr.Code)
+		}
+	})
+
+	t.Run("UnauthorizedBeforeBody", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/photos/upload", iotest.ErrReader(errors.New("body read")))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+		req.Header.Set("Authorization", "Bearer wrong")
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", rr.Code)
+		}
+	})
+
+	t.Run("TokenAfterPhotos", func(t *testing.T) {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, _ := mw.CreateFormFile("photo", "a.jpg")
+		fw.Write(jpg.Bytes())
+		mw.WriteField("token", "secret")
+		mw.Close()
+
+		req := httptest.NewRequest("POST", "/api/photos/upload", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", rr.Code)
+		}
+	})
+
+	t.Run("PartialFailure", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, uploadRequest(t, "secret", map[string][]byte{"a.jpg": jpg.Bytes(), "b.jpg": []byte("hello")}, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", rr.Code)
+		}
+		if entries, _ := os.ReadDir(inbox); len(entries) != 0 {
+			t.Errorf("Expected no photos kept from a failed request, got %d", len(entries))
+		}
+	})
+
+	t.Run("NotAnImage", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, uploadRequest(t, "secret", map[string][]byte{"a.jpg": []byte("hello")}, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		srv.server.Handler.ServeHTTP(rr, uploadRequest(t, "secret", map[string][]byte{"big.jpg": make([]byte, 3<<20)}, nil))
+		if rr.Code == http.StatusCreated {
+			t.Errorf("Expected oversized upload to be rejected")
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := uploadRequest(t, "", map[string][]byte{"../Grandma's photo.jpg": jpg.Bytes()}, map[string]string{"token": "secret", "play_next": "on"})
+		srv.server.Handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		entries, _ := os.ReadDir(inbox)
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 file in inbox, got %d", len(entries))
+		}
+		saved := filepath.Join(inbox, entries[0].Name())
+
+		if photos := srv.scannerMgr.GetPhotos(); len(photos) != 1 || photos[0] != saved {
+			t.Errorf("Expected upload to be available immediately, got %v", photos)
+		}
+		// The rendered image takes queued uploads from the same playlist.
+		if next, _ := srv.backgroundPhoto(time.Now()); next != saved {
+			t.Errorf("Expected upload to be the next background, got %s", next)
+		}
+		if srv.photoSkips != 1 {
+			t.Errorf("Expected play_next to skip the current photo, got %d skips", srv.photoSkips)
+		}
+	})
+}
+
+func TestUploadHandler_Disabled(t *testing.T) {
+	srv := New(&config.Config{})
+
+	rr := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, uploadRequest(t, "secret", map[string][]byte{"a.jpg": []byte("x")}, nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 when uploads are disabled, got %d", rr.Code)
+	}
+}
+
+func TestUploadHandler_SeveralPhotos(t *testing.T) {
+	photosDir := t.TempDir()
+	inbox := filepath.Join(photosDir, "inbox")
+
+	srv := New(&config.Config{
+		Slideshow: config.SlideshowConfig{
+			Sources: []config.SourceConfig{{Type: "local", Path: photosDir}},
+			Upload:  &config.UploadConfig{Token: "secret", Dir: inbox, MaxSizeMB: 1},
+		},
+	})
+
+	// Noise does not compress, so each photo stays just under the 1 MB
+	// limit while the request as a whole is well over it.
+	rng := rand.New(rand.NewSource(1))
+	files := map[string][]byte{}
+	total := 0
+	for _, name := range []string{"a.png", "b.png", "c.png", "d.png"} {
+		img := image.NewRGBA(image.Rect(0, 0, 420, 420))
+		rng.Read(img.Pix)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() >= 1<<20 {
+			t.Fatalf("Test photo is %d bytes, over the limit", buf.Len())
+		}
+		files[name] = buf.Bytes()
+		total += buf.Len()
+	}
+	if total <= 2<<20 {
+		t.Fatalf("Test photos total %d bytes, want more than 2 MB", total)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.server.Handler.ServeHTTP(rr, uploadRequest(t, "secret", files, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if entries, _ := os.ReadDir(inbox); len(entries) != len(files) {
+		t.Errorf("Expected %d photos in inbox, got %d", len(files), len(entries))
+	}
+}
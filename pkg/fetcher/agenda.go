@@ -0,0 +1,116 @@
+package fetcher
+
+import (
+	"time"
+
+	"bros_kiosk/pkg/textutil"
+
+	"github.com/goodsign/monday"
+)
+
+// DefaultDaysAhead is the number of days after today a calendar shows when
+// no window is configured.
+const DefaultDaysAhead = 7
+
+// CalendarOptions controls which events a calendar section shows.
+type CalendarOptions struct {
+	// DaysAhead is the number of days after today to include.
+	DaysAhead int
+	// MaxEvents caps the number of events; 0 means no limit.
+	MaxEvents int
+	// HidePast drops events that have already ended today.
+	HidePast bool
+	// HideAllDay drops all-day events.
+	HideAllDay bool
+	// Location is the display time zone that days are counted in.
+	Location *time.Location
+	// Locale is the language of the day labels, such as "de_DE".
+	Locale string
+}
+
+// CalendarDay is a day header of the agenda. Its events are
+// Events[Index:Index+Count] of the CalendarData.
+type CalendarDay struct {
+	Date  string `json:"date"`
+	Label string `json:"label"`
+	Index int    `json:"index"`
+	Count int    `json:"count"`
+}
+
+// calendarWindow returns the span a calendar covers: from the start of today
+// to the end of the day daysAhead days later.
+func calendarWindow(now time.Time, loc *time.Location, daysAhead int) (time.Time, time.Time) {
+	if loc == nil {
+		loc = time.Local
+	}
+	if daysAhead <= 0 {
+		daysAhead = DefaultDaysAhead
+	}
+	today := startOfDay(now.In(loc))
+	return today, today.AddDate(0, 0, daysAhead+1)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// arrangeAgenda applies opts to events sorted by start and groups the result
+// by day. Events that started before today but are still running are listed
+// under today.
+func arrangeAgenda(events []CalendarEvent, now time.Time, opts CalendarOptions) ([]CalendarEvent, []CalendarDay) {
+	from, to := calendarWindow(now, opts.Location, opts.DaysAhead)
+
+	kept := make([]CalendarEvent, 0, len(events))
+	for _, e := range events {
+		if opts.HideAllDay && e.AllDay {
+			continue
+		}
+		if !e.overlaps(from, to) {
+			continue
+		}
+		if opts.HidePast && !e.overlaps(now, to) {
+			continue
+		}
+		kept = append(kept, e)
+		if opts.MaxEvents > 0 && len(kept) == opts.MaxEvents {
+			break
+		}
+	}
+
+	days := make([]CalendarDay, 0)
+	for i, e := range kept {
+		day := startOfDay(e.Start.In(from.Location()))
+		if day.Before(from) {
+			day = from
+		}
+		date := day.Format("2006-01-02")
+		if n := len(days); n > 0 && days[n-1].Date == date {
+			days[n-1].Count++
+			continue
+		}
+		days = append(days, CalendarDay{
+			Date:  date,
+			Label: dayLabel(day, from, opts.Locale),
+			Index: i,
+			Count: 1,
+		})
+	}
+	return kept, days
+}
+
+// dayLabel names a day relative to today in the language of locale:
+// "Today", "Tomorrow", the weekday within the coming week, or the weekday
+// and date beyond it.
+func dayLabel(day, today time.Time, locale string) string {
+	switch {
+	case day.Equal(today):
+		label, _ := textutil.RelativeDay(0, locale)
+		return label
+	case day.Equal(today.AddDate(0, 0, 1)):
+		label, _ := textutil.RelativeDay(1, locale)
+		return label
+	case day.Before(today.AddDate(0, 0, 7)):
+		return monday.Format(day, "Monday", textutil.DateLocale(locale))
+	}
+	return monday.Format(day, "Monday, Jan 2", textutil.DateLocale(locale))
+}
@@ -0,0 +1,137 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArrangeAgenda(t *testing.T) {
+	// Wednesday afternoon.
+	now := time.Date(2026, 3, 18, 14, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+	event := func(summary string, start, end time.Time) CalendarEvent {
+		return CalendarEvent{Summary: summary, Start: start, End: end}
+	}
+	events := []CalendarEvent{
+		event("Trip", at(16, 8), at(19, 8)),
+		{Summary: "Holiday", Start: at(18, 0), End: at(19, 0), AllDay: true},
+		event("Breakfast", at(18, 8), at(18, 9)),
+		event("Meeting", at(18, 15), at(18, 16)),
+		event("Dinner", at(19, 19), at(19, 21)),
+		event("Dentist", at(21, 10), at(21, 11)),
+		event("Party", at(28, 20), at(28, 23)),
+		event("Far away", at(31, 9), at(31, 10)),
+	}
+
+	tests := []struct {
+		name       string
+		opts       CalendarOptions
+		wantEvents []string
+		wantDays   []CalendarDay
+	}{
+		{
+			name:       "Defaults",
+			opts:       CalendarOptions{Location: time.UTC},
+			wantEvents: []string{"Trip", "Holiday", "Breakfast", "Meeting", "Dinner", "Dentist"},
+			wantDays: []CalendarDay{
+				{Date: "2026-03-18", Label: "Today", Index: 0, Count: 4},
+				{Date: "2026-03-19", Label: "Tomorrow", Index: 4, Count: 1},
+				{Date: "2026-03-21", Label: "Saturday", Index: 5, Count: 1},
+			},
+		},
+		{
+			name:       "HidePastAndAllDay",
+			opts:       CalendarOptions{Location: time.UTC, HidePast: true, HideAllDay: true},
+			wantEvents: []string{"Trip", "Meeting", "Dinner", "Dentist"},
+			wantDays: []CalendarDay{
+				{Date: "2026-03-18", Label: "Today", Index: 0, Count: 2},
+				{Date: "2026-03-19", Label: "Tomorrow", Index: 2, Count: 1},
+				{Date: "2026-03-21", Label: "Saturday", Index: 3, Count: 1},
+			},
+		},
+		{
+			name:       "MaxEvents",
+			opts:       CalendarOptions{Location: time.UTC, MaxEvents: 2, HideAllDay: true},
+			wantEvents: []string{"Trip", "Breakfast"},
+			wantDays: []CalendarDay{
+				{Date: "2026-03-18", Label: "Today", Index: 0, Count: 2},
+			},
+		},
+		{
+			name:       "DaysAhead",
+			opts:       CalendarOptions{Location: time.UTC, DaysAhead: 10, HidePast: true},
+			wantEvents: []string{"Trip", "Holiday", "Meeting", "Dinner", "Dentist", "Party"},
+			wantDays: []CalendarDay{
+				{Date: "2026-03-18", Label: "Today", Index: 0, Count: 3},
+				{Date: "2026-03-19", Label: "Tomorrow", Index: 3, Count: 1},
+				{Date: "2026-03-21", Label: "Saturday", Index: 4, Count: 1},
+				{Date: "2026-03-28", Label: "Saturday, Mar 28", Index: 5, Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, days := arrangeAgenda(events, now, tt.opts)
+			if len(got) != len(tt.wantEvents) {
+				t.Fatalf("Expected %d events, got %d: %+v", len(tt.wantEvents), len(got), got)
+			}
+			for i, want := range tt.wantEvents {
+				if got[i].Summary != want {
+					t.Errorf("Event %d = %q, want %q", i, got[i].Summary, want)
+				}
+			}
+			if len(days) != len(tt.wantDays) {
+				t.Fatalf("Expected %d days, got %d: %+v", len(tt.wantDays), len(days), days)
+			}
+			for i, want := range tt.wantDays {
+				if days[i] != want {
+					t.Errorf("Day %d = %+v, want %+v", i, days[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestArrangeAgenda_DisplayZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	// 18:00 and 23:30 UTC on March 18 are both on March 19 in Tokyo.
+	now := time.Date(2026, 3, 18, 18, 0, 0, 0, time.UTC)
+	events := []CalendarEvent{{
+		Summary: "Late call",
+		Start:   time.Date(2026, 3, 18, 23, 30, 0, 0, time.UTC),
+		End:     time.Date(2026, 3, 19, 0, 30, 0, 0, time.UTC),
+	}}
+
+	_, days := arrangeAgenda(events, now, CalendarOptions{Location: tokyo})
+	if len(days) != 1 || days[0].Date != "2026-03-19" || days[0].Label != "Today" {
+		t.Errorf("Unexpected days: %+v", days)
+	}
+}
+
+func TestDayLabel_Locale(t *testing.T) {
+	today := time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		days   int
+		locale string
+		want   string
+	}{
+		{0, "de_DE", "Heute"},
+		{1, "de_DE", "Morgen"},
+		{3, "de_DE", "Samstag"},
+		{10, "de_DE", "Samstag, Mär 28"},
+		{3, "fr_FR", "samedi"},
+		{3, "de-DE", "Samstag"},
+		{10, "", "Saturday, Mar 28"},
+	}
+	for _, tt := range tests {
+		if got := dayLabel(today.AddDate(0, 0, tt.days), today, tt.locale); got != tt.want {
+			t.Errorf("dayLabel(+%d, %q) = %q, want %q", tt.days, tt.locale, got, tt.want)
+		}
+	}
+}
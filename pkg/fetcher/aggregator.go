@@ -3,53 +3,131 @@ package fetcher
 import (
 	"context"
 	"sort"
+	"sync"
+	"time"
 )
 
+// DefaultSourceTimeout bounds how long the aggregator waits for a single
+// calendar source.
+const DefaultSourceTimeout = 20 * time.Second
+
+// Source states reported in CalendarSourceStatus.
+const (
+	SourceOK    = "ok"
+	SourceStale = "stale"
+	SourceError = "error"
+)
+
+// CalendarSourceStatus reports how the last fetch of one calendar went.
+// A stale source failed but still contributes the events of its last
+// successful fetch.
+type CalendarSourceStatus struct {
+	Name        string    `json:"name"`
+	State       string    `json:"state"`
+	Error       string    `json:"error,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+}
+
 type CalendarAggregator struct {
 	name     string
 	fetchers []Fetcher
+	opts     CalendarOptions
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	last []sourceEvents
+}
+
+// sourceEvents are the events of a source's last successful fetch.
+type sourceEvents struct {
+	events []CalendarEvent
+	at     time.Time
 }
 
 func NewCalendarAggregator(name string, fetchers []Fetcher) *CalendarAggregator {
 	return &CalendarAggregator{
 		name:     name,
 		fetchers: fetchers,
+		timeout:  DefaultSourceTimeout,
+		last:     make([]sourceEvents, len(fetchers)),
+	}
+}
+
+// SetOptions sets the window, limits and time zone applied to the merged
+// events.
+func (a *CalendarAggregator) SetOptions(opts CalendarOptions) {
+	a.opts = opts
+}
+
+// SetSourceTimeout sets how long each source may take. Values <= 0 restore
+// the default.
+func (a *CalendarAggregator) SetSourceTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultSourceTimeout
 	}
+	a.timeout = timeout
 }
 
 func (a *CalendarAggregator) Name() string {
 	return a.name
 }
 
+// Fetch queries all sources concurrently and merges their events. A failing
+// source keeps its last good events; Fetch only fails when no source has
+// any events to show.
 func (a *CalendarAggregator) Fetch(ctx context.Context) (interface{}, error) {
+	results, sources := fetchSources(ctx, a.name, a.fetchers, a.timeout, func(data interface{}) bool {
+		cd, ok := data.(*CalendarData)
+		return ok && cd != nil
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
 	allEvents := make([]CalendarEvent, 0)
-	errors := make([]error, 0)
-
-	// Fetch from all sources
-	for _, f := range a.fetchers {
-		data, err := f.Fetch(ctx)
-		if err != nil {
-			errors = append(errors, err)
-			continue
+	available := false
+	for i, res := range results {
+		if res.err != nil {
+			if !a.last[i].at.IsZero() {
+				sources[i].State = SourceStale
+				sources[i].LastSuccess = a.last[i].at
+				available = true
+			}
+		} else {
+			cd := res.data.(*CalendarData)
+			// Calendars without a configured color get one from the palette.
+			for j := range cd.Events {
+				if cd.Events[j].Color == "" {
+					cd.Events[j].Color = calendarPalette[i%len(calendarPalette)]
+				}
+			}
+			a.last[i] = sourceEvents{events: cd.Events, at: sources[i].LastSuccess}
+			available = true
 		}
+		allEvents = append(allEvents, a.last[i].events...)
+	}
 
-		if calData, ok := data.(*CalendarData); ok {
-			allEvents = append(allEvents, calData.Events...)
-		}
+	if err := firstError(results); err != nil && !available {
+		return nil, err
 	}
 
+	// The same meeting in several calendars is kept from the first one.
+	allEvents = dedupeEvents(allEvents)
+
 	// Sort events by start time
-	sort.Slice(allEvents, func(i, j int) bool {
+	sort.SliceStable(allEvents, func(i, j int) bool {
 		return allEvents[i].Start.Before(allEvents[j].Start)
 	})
 
-	// If all failed, return error
-	if len(allEvents) == 0 && len(errors) == len(a.fetchers) && len(errors) > 0 {
-		return nil, errors[0]
-	}
+	events, days := arrangeAgenda(allEvents, now, a.opts)
+	merged, _ := arrangeAgenda(allEvents, now, CalendarOptions{DaysAhead: a.opts.DaysAhead, Location: a.opts.Location})
 
 	return &CalendarData{
-		Source: a.name,
-		Events: allEvents,
+		Source:  a.name,
+		Events:  events,
+		Days:    days,
+		Sources: sources,
+		merged:  merged,
 	}, nil
 }
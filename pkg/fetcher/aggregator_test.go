@@ -0,0 +1,184 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func serveICS(t *testing.T, summary string, start time.Time) *httptest.Server {
+	t.Helper()
+	ics := fmt.Sprintf("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:%s\r\nSUMMARY:%s\r\nDTSTART:%s\r\nDURATION:PT1H\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		summary, summary, start.UTC().Format(icalDateTimeUTCFormat))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(ics))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCalendarAggregator_SourceColors(t *testing.T) {
+	now := time.Now()
+	school := NewICalFetcher("School", serveICS(t, "Sports day", now.Add(2*time.Hour)).URL)
+	school.SetColor("#ff0000")
+	work := NewICalFetcher("Work", serveICS(t, "Review", now.Add(3*time.Hour)).URL)
+
+	agg := NewCalendarAggregator("cal", []Fetcher{school, work})
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	events := data.(*CalendarData).Events
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	if events[0].Calendar != "School" || events[0].Color != "#ff0000" {
+		t.Errorf("Expected configured calendar and color, got %q %q", events[0].Calendar, events[0].Color)
+	}
+	// The second calendar has no color and gets the second palette entry.
+	if events[1].Calendar != "Work" || events[1].Color != calendarPalette[1] {
+		t.Errorf("Expected palette color %q for Work, got %q %q", calendarPalette[1], events[1].Calendar, events[1].Color)
+	}
+}
+
+// stubCalendar returns its events, or err when set.
+type stubCalendar struct {
+	name   string
+	events []CalendarEvent
+	err    error
+	delay  time.Duration
+}
+
+func (s *stubCalendar) Name() string { return s.name }
+
+func (s *stubCalendar) Fetch(ctx context.Context) (interface{}, error) {
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &CalendarData{Source: s.name, Events: append([]CalendarEvent(nil), s.events...)}, nil
+}
+
+func TestCalendarAggregator_SourceStatus(t *testing.T) {
+	soon := time.Now().Add(time.Hour)
+	home := &stubCalendar{name: "Home", events: []CalendarEvent{{Summary: "Dinner", Start: soon, End: soon.Add(time.Hour)}}}
+	work := &stubCalendar{name: "Work", events: []CalendarEvent{{Summary: "Review", Start: soon, End: soon.Add(time.Hour)}}}
+	slow := &stubCalendar{name: "Slow", delay: time.Second}
+
+	agg := NewCalendarAggregator("cal", []Fetcher{home, work, slow})
+	agg.SetSourceTimeout(50 * time.Millisecond)
+
+	states := func(data interface{}) []string {
+		var got []string
+		for _, s := range data.(*CalendarData).Sources {
+			got = append(got, s.State)
+		}
+		return got
+	}
+	wantStates := func(t *testing.T, data interface{}, want ...string) {
+		t.Helper()
+		got := states(data)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("States = %v, want %v", got, want)
+		}
+	}
+
+	start := time.Now()
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Slow source was not cut off, took %v", elapsed)
+	}
+	wantStates(t, data, SourceOK, SourceOK, SourceError)
+	if n := len(data.(*CalendarData).Events); n != 2 {
+		t.Errorf("Expected 2 events, got %d", n)
+	}
+	if got := data.(*CalendarData).Degraded(); got != "unavailable: Slow" {
+		t.Errorf("Degraded() = %q", got)
+	}
+
+	// A failing source keeps its last good events.
+	work.err = fmt.Errorf("server down")
+	data, err = agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	wantStates(t, data, SourceOK, SourceStale, SourceError)
+	cd := data.(*CalendarData)
+	if len(cd.Events) != 2 {
+		t.Errorf("Expected stale events to be kept, got %d events", len(cd.Events))
+	}
+	if cd.Sources[1].Error != "server down" || cd.Sources[1].LastSuccess.IsZero() {
+		t.Errorf("Unexpected stale status: %+v", cd.Sources[1])
+	}
+
+	// Recovery clears the flag.
+	work.err = nil
+	slow.delay = 0
+	data, _ = agg.Fetch(context.Background())
+	wantStates(t, data, SourceOK, SourceOK, SourceOK)
+	if got := data.(*CalendarData).Degraded(); got != "" {
+		t.Errorf("Degraded() = %q, want empty", got)
+	}
+}
+
+func TestCalendarAggregator_AllFailed(t *testing.T) {
+	agg := NewCalendarAggregator("cal", []Fetcher{
+		&stubCalendar{name: "A", err: fmt.Errorf("a failed")},
+		&stubCalendar{name: "B", err: fmt.Errorf("b failed")},
+	})
+	if _, err := agg.Fetch(context.Background()); err == nil {
+		t.Error("Expected error when every source fails")
+	}
+}
+
+func TestCalendarAggregator_Dedupe(t *testing.T) {
+	soon := time.Now().Add(time.Hour)
+	meeting := CalendarEvent{UID: "planning", Summary: "Planning", Start: soon, End: soon.Add(time.Hour)}
+	shared := &stubCalendar{name: "Shared", events: []CalendarEvent{meeting}}
+	personal := &stubCalendar{name: "Personal", events: []CalendarEvent{meeting, {UID: "gym", Summary: "Gym", Start: soon, End: soon.Add(time.Hour)}}}
+
+	data, err := NewCalendarAggregator("cal", []Fetcher{shared, personal}).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if n := len(data.(*CalendarData).Events); n != 2 {
+		t.Errorf("Expected the shared meeting once, got %d events", n)
+	}
+}
+
+func TestCalendarAggregator_AllEvents(t *testing.T) {
+	now := time.Now()
+	cal := &stubCalendar{name: "Work", events: []CalendarEvent{
+		{UID: "a", Summary: "First", Start: now.Add(time.Minute), End: now.Add(time.Hour)},
+		{UID: "b", Summary: "Second", Start: now.Add(2 * time.Minute), End: now.Add(time.Hour)},
+		{UID: "c", Summary: "All day", Start: startOfDay(now), End: startOfDay(now).AddDate(0, 0, 1), AllDay: true},
+	}}
+	agg := NewCalendarAggregator("cal", []Fetcher{cal})
+	agg.SetOptions(CalendarOptions{MaxEvents: 1, HideAllDay: true})
+
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	cd := data.(*CalendarData)
+	if len(cd.Events) != 1 {
+		t.Errorf("Expected 1 shown event, got %d", len(cd.Events))
+	}
+	// The display limits do not apply to the full list.
+	if all := cd.AllEvents(); len(all) != 3 {
+		t.Errorf("Expected 3 events before the display limits, got %d", len(all))
+	}
+}
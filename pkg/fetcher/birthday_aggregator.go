@@ -0,0 +1,60 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+)
+
+// BirthdayAggregator reads several address books concurrently and merges
+// their birthdays.
+type BirthdayAggregator struct {
+	name     string
+	fetchers []Fetcher
+	opts     BirthdayOptions
+	timeout  time.Duration
+}
+
+// NewBirthdayAggregator creates a new BirthdayAggregator.
+func NewBirthdayAggregator(name string, fetchers []Fetcher) *BirthdayAggregator {
+	return &BirthdayAggregator{
+		name:     name,
+		fetchers: fetchers,
+		timeout:  DefaultSourceTimeout,
+	}
+}
+
+// SetOptions sets the window, limit and time zone applied to the merged
+// birthdays.
+func (a *BirthdayAggregator) SetOptions(opts BirthdayOptions) {
+	a.opts = opts
+}
+
+// Name returns the aggregator name.
+func (a *BirthdayAggregator) Name() string {
+	return a.name
+}
+
+// Fetch reads all address books and returns the next birthdays. It only
+// fails when every address book fails.
+func (a *BirthdayAggregator) Fetch(ctx context.Context) (interface{}, error) {
+	results, sources := fetchSources(ctx, a.name, a.fetchers, a.timeout, func(data interface{}) bool {
+		bd, ok := data.(*BirthdayData)
+		return ok && bd != nil
+	})
+	if err := firstError(results); err != nil {
+		return nil, err
+	}
+
+	birthdays := make([]Birthday, 0)
+	for _, res := range results {
+		if res.err == nil {
+			birthdays = append(birthdays, res.data.(*BirthdayData).Birthdays...)
+		}
+	}
+
+	return &BirthdayData{
+		Source:    a.name,
+		Birthdays: arrangeBirthdays(birthdays, time.Now(), a.opts),
+		Sources:   sources,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stubBirthdays returns its birthdays, or err when set.
+type stubBirthdays struct {
+	name      string
+	birthdays []Birthday
+	err       error
+}
+
+func (s *stubBirthdays) Name() string { return s.name }
+
+func (s *stubBirthdays) Fetch(ctx context.Context) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &BirthdayData{Source: s.name, Birthdays: append([]Birthday(nil), s.birthdays...)}, nil
+}
+
+func TestBirthdayAggregator_Fetch(t *testing.T) {
+	today := time.Now()
+	tomorrow := today.AddDate(0, 0, 1)
+	agg := NewBirthdayAggregator("birthdays", []Fetcher{
+		&stubBirthdays{name: "Home", birthdays: []Birthday{{Name: "Max", Kind: KindBirthday, month: int(tomorrow.Month()), day: tomorrow.Day()}}},
+		&stubBirthdays{name: "Work", birthdays: []Birthday{{Name: "Jane", Kind: KindBirthday, year: today.Year() - 30, month: int(today.Month()), day: today.Day()}}},
+		&stubBirthdays{name: "Club", err: fmt.Errorf("unauthorized")},
+	})
+	agg.SetOptions(BirthdayOptions{Location: time.Local})
+
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	bd := data.(*BirthdayData)
+	if len(bd.Birthdays) != 2 || bd.Birthdays[0].Name != "Jane" || !bd.Birthdays[0].Today || bd.Birthdays[0].Age != 30 || bd.Birthdays[1].Name != "Max" {
+		t.Fatalf("Unexpected birthdays: %+v", bd.Birthdays)
+	}
+	if len(bd.Sources) != 3 || bd.Sources[2].State != SourceError || bd.Degraded() == "" {
+		t.Errorf("Unexpected sources: %+v", bd.Sources)
+	}
+
+	failing := NewBirthdayAggregator("birthdays", []Fetcher{&stubBirthdays{name: "Club", err: fmt.Errorf("unauthorized")}})
+	if _, err := failing.Fetch(context.Background()); err == nil {
+		t.Error("Expected error when every address book fails")
+	}
+}
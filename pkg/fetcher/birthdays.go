@@ -0,0 +1,184 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultBirthdayCount is the number of people a birthdays section shows
+// when no limit is configured.
+const DefaultBirthdayCount = 5
+
+// Birthday is the next occurrence of a contact's birthday or anniversary.
+type Birthday struct {
+	Name string `json:"name"`
+	// Kind is KindBirthday or KindAnniversary.
+	Kind string `json:"kind"`
+	// Date is the next occurrence, as YYYY-MM-DD.
+	Date string `json:"date"`
+	// Label names the day relative to today, e.g. "Tomorrow".
+	Label string `json:"label"`
+	// Age is the number of years completed on Date; 0 when the year is not
+	// known.
+	Age       int    `json:"age,omitempty"`
+	DaysUntil int    `json:"days_until"`
+	Today     bool   `json:"today"`
+	Source    string `json:"source,omitempty"`
+
+	year, month, day int
+}
+
+// BirthdayData represents the upcoming birthdays of a birthdays section.
+type BirthdayData struct {
+	Source    string     `json:"source"`
+	Birthdays []Birthday `json:"birthdays"`
+	// Sources reports the state of each address book merged into the data.
+	Sources []CalendarSourceStatus `json:"sources,omitempty"`
+}
+
+// Degraded names the address books that failed their last fetch.
+func (d *BirthdayData) Degraded() string {
+	return degradedSources(d.Sources)
+}
+
+// BirthdayOptions controls which birthdays a birthdays section shows.
+type BirthdayOptions struct {
+	// MaxItems caps the number of entries; 0 means DefaultBirthdayCount.
+	MaxItems int
+	// DaysAhead drops dates further away; 0 means a full year.
+	DaysAhead int
+	// Location is the display time zone that days are counted in.
+	Location *time.Location
+	// Locale is the language of the day labels, such as "de_DE".
+	Locale string
+}
+
+// VCardFileFetcher reads birthdays from a local .vcf file.
+type VCardFileFetcher struct {
+	name string
+	path string
+}
+
+// NewVCardFileFetcher creates a new instance of VCardFileFetcher.
+func NewVCardFileFetcher(name, path string) *VCardFileFetcher {
+	return &VCardFileFetcher{name: name, path: path}
+}
+
+// Name returns the fetcher name.
+func (f *VCardFileFetcher) Name() string {
+	return f.name
+}
+
+// Fetch reads the file.
+func (f *VCardFileFetcher) Fetch(ctx context.Context) (interface{}, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vcard file: %w", err)
+	}
+	defer file.Close()
+
+	dates, err := parseVCards(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vcard file: %w", err)
+	}
+	return &BirthdayData{Source: f.name, Birthdays: birthdaysOf(dates, f.name)}, nil
+}
+
+func birthdaysOf(dates []contactDate, source string) []Birthday {
+	birthdays := make([]Birthday, 0, len(dates))
+	for _, d := range dates {
+		birthdays = append(birthdays, Birthday{
+			Name:   d.name,
+			Kind:   d.kind,
+			Source: source,
+			year:   d.year,
+			month:  d.month,
+			day:    d.day,
+		})
+	}
+	return birthdays
+}
+
+// arrangeBirthdays computes the next occurrence of each date from today on,
+// drops the ones beyond the window and people listed twice, and returns the
+// nearest first.
+func arrangeBirthdays(birthdays []Birthday, now time.Time, opts BirthdayOptions) []Birthday {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	today := startOfDay(now.In(loc))
+	limit := today.AddDate(1, 0, 0)
+	if opts.DaysAhead > 0 {
+		limit = today.AddDate(0, 0, opts.DaysAhead+1)
+	}
+
+	kept := make([]Birthday, 0, len(birthdays))
+	seen := make(map[string]bool)
+	for _, b := range birthdays {
+		next := nextOccurrence(b.month, b.day, today)
+		if !next.Before(limit) {
+			continue
+		}
+		key := strings.ToLower(b.Name) + "|" + b.Kind + "|" + next.Format("0102")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		b.Date = next.Format("2006-01-02")
+		b.Label = dueLabel(next, today, opts.Locale)
+		b.DaysUntil = daysBetween(today, next)
+		b.Today = b.DaysUntil == 0
+		b.Age = 0
+		if b.year > 0 && next.Year() > b.year {
+			b.Age = next.Year() - b.year
+		}
+		kept = append(kept, b)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].DaysUntil != kept[j].DaysUntil {
+			return kept[i].DaysUntil < kept[j].DaysUntil
+		}
+		return strings.ToLower(kept[i].Name) < strings.ToLower(kept[j].Name)
+	})
+
+	n := opts.MaxItems
+	if n <= 0 {
+		n = DefaultBirthdayCount
+	}
+	if len(kept) > n {
+		kept = kept[:n]
+	}
+	return kept
+}
+
+// nextOccurrence returns the first month/day on or after today. February 29
+// falls on February 28 in common years.
+func nextOccurrence(month, day int, today time.Time) time.Time {
+	for year := today.Year(); ; year++ {
+		d := day
+		if month == 2 && day == 29 && !isLeap(year) {
+			d = 28
+		}
+		t := time.Date(year, time.Month(month), d, 0, 0, 0, 0, today.Location())
+		if !t.Before(today) {
+			return t
+		}
+	}
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// daysBetween counts the calendar days from one midnight to another, also
+// across daylight saving changes.
+func daysBetween(from, to time.Time) int {
+	return int((to.Sub(from) + 12*time.Hour) / (24 * time.Hour))
+}
@@ -0,0 +1,95 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArrangeBirthdays(t *testing.T) {
+	// Wednesday, March 18.
+	now := time.Date(2026, 3, 18, 14, 0, 0, 0, time.UTC)
+	birthdays := []Birthday{
+		{Name: "Jane", Kind: KindBirthday, year: 1985, month: 3, day: 18},
+		{Name: "Max", Kind: KindBirthday, month: 3, day: 19},
+		{Name: "Old friend", Kind: KindBirthday, year: 1950, month: 3, day: 17},
+		{Name: "Leap", Kind: KindBirthday, year: 2000, month: 2, day: 29},
+		{Name: "Jane & John", Kind: KindAnniversary, year: 2010, month: 3, day: 25},
+		// Listed in two address books.
+		{Name: "Max", Kind: KindBirthday, month: 3, day: 19, Source: "Work"},
+	}
+
+	tests := []struct {
+		name string
+		opts BirthdayOptions
+		want []Birthday
+	}{
+		{
+			name: "Defaults",
+			opts: BirthdayOptions{Location: time.UTC},
+			want: []Birthday{
+				{Name: "Jane", Date: "2026-03-18", Label: "Today", Age: 41, DaysUntil: 0, Today: true},
+				{Name: "Max", Date: "2026-03-19", Label: "Tomorrow", DaysUntil: 1},
+				{Name: "Jane & John", Date: "2026-03-25", Label: "Mar 25", Age: 16, DaysUntil: 7},
+				{Name: "Leap", Date: "2027-02-28", Label: "Feb 28", Age: 27, DaysUntil: 347},
+				{Name: "Old friend", Date: "2027-03-17", Label: "Mar 17", Age: 77, DaysUntil: 364},
+			},
+		},
+		{
+			name: "DaysAheadAndMaxItems",
+			opts: BirthdayOptions{Location: time.UTC, DaysAhead: 30, MaxItems: 2},
+			want: []Birthday{
+				{Name: "Jane", Date: "2026-03-18", Label: "Today", Age: 41, DaysUntil: 0, Today: true},
+				{Name: "Max", Date: "2026-03-19", Label: "Tomorrow", DaysUntil: 1},
+			},
+		},
+		{
+			name: "Locale",
+			opts: BirthdayOptions{Location: time.UTC, DaysAhead: 7, Locale: "de_DE"},
+			want: []Birthday{
+				{Name: "Jane", Date: "2026-03-18", Label: "Heute", Age: 41, DaysUntil: 0, Today: true},
+				{Name: "Max", Date: "2026-03-19", Label: "Morgen", DaysUntil: 1},
+				{Name: "Jane & John", Date: "2026-03-25", Label: "Mär 25", Age: 16, DaysUntil: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := arrangeBirthdays(birthdays, now, tt.opts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d birthdays, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, want := range tt.want {
+				g := got[i]
+				if g.Name != want.Name || g.Date != want.Date || g.Label != want.Label || g.Age != want.Age ||
+					g.DaysUntil != want.DaysUntil || g.Today != want.Today {
+					t.Errorf("Birthday %d = %+v, want %+v", i, g, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVCardFileFetcher_Fetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contacts.vcf")
+	vcf := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Jane Doe\r\nBDAY:1985-04-12\r\nEND:VCARD\r\n"
+	if err := os.WriteFile(path, []byte(vcf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := NewVCardFileFetcher("Contacts", path).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	birthdays := data.(*BirthdayData).Birthdays
+	if len(birthdays) != 1 || birthdays[0].Name != "Jane Doe" || birthdays[0].Source != "Contacts" {
+		t.Errorf("Unexpected birthdays: %+v", birthdays)
+	}
+
+	if _, err := NewVCardFileFetcher("Missing", filepath.Join(t.TempDir(), "none.vcf")).Fetch(context.Background()); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
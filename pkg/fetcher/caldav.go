@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/emersion/go-ical"
@@ -16,15 +17,31 @@ type CalDAVFetcher struct {
 	url      string
 	username string
 	password string
+	loc      *time.Location
+	days     int
+	color    string
+	rules    *EventRules
+	remind   *ReminderRule
+	selected []string
+	// component is the component type the selected calendars must hold.
+	component string
+
+	// mu guards the discovered client and calendar paths, which are kept
+	// between fetches.
+	mu     sync.Mutex
+	client *caldav.Client
+	paths  []string
 }
 
 // NewCalDAVFetcher creates a new instance of CalDAVFetcher.
 func NewCalDAVFetcher(name, url, username, password string) *CalDAVFetcher {
 	return &CalDAVFetcher{
-		name:     name,
-		url:      url,
-		username: username,
-		password: password,
+		name:      name,
+		url:       url,
+		username:  username,
+		password:  password,
+		loc:       time.Local,
+		component: ical.CompEvent,
 	}
 }
 
@@ -33,6 +50,40 @@ func (f *CalDAVFetcher) SetName(name string) {
 	f.name = name
 }
 
+// SetLocation sets the display time zone. Floating times and all-day dates
+// are interpreted in it.
+func (f *CalDAVFetcher) SetLocation(loc *time.Location) {
+	f.loc = loc
+}
+
+// SetDaysAhead sets how many days after today are fetched.
+func (f *CalDAVFetcher) SetDaysAhead(days int) {
+	f.days = days
+}
+
+// SetColor sets the color the calendar's events are marked with.
+func (f *CalDAVFetcher) SetColor(color string) {
+	f.color = color
+}
+
+// SetRules sets the privacy, rewrite and attendee rules applied to the
+// calendar's events.
+func (f *CalDAVFetcher) SetRules(rules *EventRules) {
+	f.rules = rules
+}
+
+// SetReminders sets when the calendar's events raise an alert.
+func (f *CalDAVFetcher) SetReminders(rule *ReminderRule) {
+	f.remind = rule
+}
+
+// SetCalendars selects the calendars to show by display name or path. By
+// default the calendar at the URL is shown, or else the first calendar of
+// the account that holds events.
+func (f *CalDAVFetcher) SetCalendars(names []string) {
+	f.selected = names
+}
+
 // Name returns the fetcher name.
 func (f *CalDAVFetcher) Name() string {
 	return f.name
@@ -40,100 +91,82 @@ func (f *CalDAVFetcher) Name() string {
 
 // Fetch retrieves events from the CalDAV server.
 func (f *CalDAVFetcher) Fetch(ctx context.Context) (interface{}, error) {
-	// Custom transport for Basic Auth
-	hc := &http.Client{
-		Transport: &authTransport{
-			Transport: http.DefaultTransport,
-			Username:  f.username,
-			Password:  f.password,
-		},
-		Timeout: 15 * time.Second,
-	}
-
-	client, err := caldav.NewClient(hc, f.url)
+	start, end := calendarWindow(time.Now(), f.loc, f.days)
+	cals, err := f.query(ctx, caldav.CompFilter{Name: ical.CompEvent, Start: start, End: end})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create caldav client: %w", err)
+		return nil, err
 	}
 
-	// 1. Try to find calendars (discovery)
-	calendars, err := client.FindCalendars(ctx, "")
-	if err != nil {
-		// If discovery fails at root, try to use the provided URL as the calendar path
-		return f.fetchFromCalendar(ctx, client, f.url)
+	// Recurring events come back as a master with its overridden instances;
+	// they are expanded together.
+	zones := newZoneResolver(f.loc)
+	var vevents []ical.Event
+	for _, cal := range cals {
+		zones.add(cal)
+		vevents = append(vevents, cal.Events()...)
 	}
+	events := expandEvents(vevents, start, end, zones)
+	events = f.rules.apply(events)
+	f.remind.apply(events)
+	tagEvents(events, f.name, f.color)
 
-	if len(calendars) == 0 {
-		return f.fetchFromCalendar(ctx, client, f.url)
-	}
-
-	return f.fetchFromCalendar(ctx, client, calendars[0].Path)
+	return &CalendarData{
+		Source: f.name,
+		Events: events,
+	}, nil
 }
 
-func (f *CalDAVFetcher) fetchFromCalendar(ctx context.Context, client *caldav.Client, path string) (interface{}, error) {
-	now := time.Now()
-	start := now.Add(-24 * time.Hour)
-	end := now.Add(30 * 24 * time.Hour)
+// query returns the calendar objects of the selected calendars that match
+// comp. The calendars are discovered on the first query and again after a
+// query fails.
+func (f *CalDAVFetcher) query(ctx context.Context, comp caldav.CompFilter) ([]*ical.Calendar, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client == nil {
+		client, paths, err := f.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		f.client, f.paths = client, paths
+	}
 
 	query := &caldav.CalendarQuery{
 		CompFilter: caldav.CompFilter{
-			Name: "VCALENDAR",
-			Comps: []caldav.CompFilter{
-				{
-					Name:  "VEVENT",
-					Start: start,
-					End:   end,
-				},
-			},
+			Name:  ical.CompCalendar,
+			Comps: []caldav.CompFilter{comp},
 		},
 	}
 
-	objs, err := client.QueryCalendar(ctx, path, query)
-	if err != nil {
-		return nil, fmt.Errorf("query calendar failed at %s: %w", path, err)
-	}
-
-	events := make([]CalendarEvent, 0)
-	for _, obj := range objs {
-		if obj.Data == nil {
-			continue
+	var cals []*ical.Calendar
+	for _, path := range f.paths {
+		objs, err := f.client.QueryCalendar(ctx, path, query)
+		if err != nil {
+			// The calendar may have moved or the server changed: discover
+			// again on the next query.
+			f.client, f.paths = nil, nil
+			return nil, fmt.Errorf("query calendar failed at %s: %w", path, err)
 		}
-
-		for _, e := range obj.Data.Events() {
-			event := CalendarEvent{}
-			if prop := e.Props.Get(ical.PropSummary); prop != nil {
-				event.Summary = prop.Value
-			}
-			if prop := e.Props.Get(ical.PropLocation); prop != nil {
-				event.Location = prop.Value
-			}
-			if prop := e.Props.Get(ical.PropDescription); prop != nil {
-				event.Description = prop.Value
-			}
-			if prop := e.Props.Get(ical.PropStatus); prop != nil {
-				event.Status = prop.Value
-			}
-
-			if t, err := e.DateTimeStart(time.UTC); err == nil {
-				event.Start = t
-			}
-			if t, err := e.DateTimeEnd(time.UTC); err == nil {
-				event.End = t
-			}
-
-			if prop := e.Props.Get(ical.PropDateTimeStart); prop != nil {
-				if prop.Params.Get("VALUE") == "DATE" {
-					event.AllDay = true
-				}
+		for _, obj := range objs {
+			if obj.Data != nil {
+				cals = append(cals, obj.Data)
 			}
-
-			events = append(events, event)
 		}
 	}
+	return cals, nil
+}
 
-	return &CalendarData{
-		Source: f.name,
-		Events: events,
-	}, nil
+// httpClient returns a client that authenticates with the configured
+// credentials.
+func (f *CalDAVFetcher) httpClient() *http.Client {
+	return &http.Client{
+		Transport: &authTransport{
+			Transport: http.DefaultTransport,
+			Username:  f.username,
+			Password:  f.password,
+		},
+		Timeout: 15 * time.Second,
+	}
 }
 
 type authTransport struct {
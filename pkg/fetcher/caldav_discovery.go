@@ -0,0 +1,233 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/emersion/go-webdav/caldav"
+)
+
+// discover finds the calendars to query. The current user principal and its
+// calendar-home-set are looked up from the configured URL, then from the
+// context path of /.well-known/caldav and finally from the server root. If
+// none of them lists calendars, the URL itself is queried as a calendar.
+func (f *CalDAVFetcher) discover(ctx context.Context) (*caldav.Client, []string, error) {
+	hc := f.httpClient()
+
+	for _, endpoint := range davEndpoints(ctx, hc, f.url, "caldav") {
+		client, err := caldav.NewClient(hc, endpoint)
+		if err != nil {
+			continue
+		}
+		calendars, err := findCalendars(ctx, client, endpoint)
+		if err != nil {
+			slog.Debug("CalDAV discovery failed", "source", f.name, "endpoint", endpoint, "error", err)
+			continue
+		}
+		if len(calendars) == 0 {
+			continue
+		}
+
+		paths, err := f.selectCalendars(calendars)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("CalDAV calendars discovered", "source", f.name, "calendars", paths)
+		return client, paths, nil
+	}
+
+	client, err := caldav.NewClient(hc, f.url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create caldav client: %w", err)
+	}
+	return client, []string{""}, nil
+}
+
+// davEndpoints lists the URLs CalDAV and CardDAV discovery start from,
+// without duplicates: the configured URL, the context path announced at
+// /.well-known/<service> and the server root.
+func davEndpoints(ctx context.Context, hc *http.Client, rawURL, service string) []string {
+	endpoints := []string{rawURL}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return endpoints
+	}
+
+	root := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+	for _, e := range []string{wellKnownContext(ctx, hc, root, service), root.String()} {
+		if e == "" {
+			continue
+		}
+		duplicate := false
+		for _, seen := range endpoints {
+			if strings.TrimSuffix(seen, "/") == strings.TrimSuffix(e, "/") {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// wellKnownContext resolves the context path a server announces at
+// /.well-known/caldav or /.well-known/carddav (RFC 6764). The redirect is
+// followed by hand: the HTTP client would turn a redirected PROPFIND into a
+// GET.
+func wellKnownContext(ctx context.Context, hc *http.Client, root *url.URL, service string) string {
+	wellKnown := root.ResolveReference(&url.URL{Path: "/.well-known/" + service})
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", wellKnown.String(), nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Depth", "0")
+
+	noRedirect := *hc
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		loc, err := resp.Location()
+		if err != nil {
+			return ""
+		}
+		return loc.String()
+	case resp.StatusCode == http.StatusMultiStatus:
+		return wellKnown.String()
+	}
+	return ""
+}
+
+// findCalendars lists the calendars in the home set of the current user.
+func findCalendars(ctx context.Context, client *caldav.Client, endpoint string) ([]caldav.Calendar, error) {
+	home, err := findHomeSet(ctx, client, endpoint, client.FindCalendarHomeSet)
+	if err != nil {
+		return nil, err
+	}
+	calendars, err := client.FindCalendars(ctx, home)
+	if err != nil {
+		return nil, fmt.Errorf("calendars in %s: %w", home, err)
+	}
+	return calendars, nil
+}
+
+// principalFinder is the part of the go-webdav clients that looks up the
+// current user principal.
+type principalFinder interface {
+	FindCurrentUserPrincipal(ctx context.Context) (string, error)
+}
+
+// findHomeSet looks up the calendar or address book home set of the current
+// user through homeSet. When the endpoint does not report a principal it may
+// be the principal itself, as /.well-known redirects often point straight to
+// it.
+func findHomeSet(ctx context.Context, client principalFinder, endpoint string, homeSet func(context.Context, string) (string, error)) (string, error) {
+	principal, principalErr := client.FindCurrentUserPrincipal(ctx)
+	if principalErr != nil {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", fmt.Errorf("current user principal: %w", principalErr)
+		}
+		principal = u.Path
+	}
+	home, err := homeSet(ctx, principal)
+	if err != nil {
+		if principalErr != nil {
+			return "", fmt.Errorf("current user principal: %w", principalErr)
+		}
+		return "", fmt.Errorf("home set of %s: %w", principal, err)
+	}
+	return home, nil
+}
+
+// selectCalendars picks the paths of the configured calendars, matched by
+// display name (ignoring case) or path. Without a selection it picks the
+// calendar at the configured URL, or else the first one that holds the
+// fetcher's component type.
+func (f *CalDAVFetcher) selectCalendars(calendars []caldav.Calendar) ([]string, error) {
+	if len(f.selected) == 0 {
+		if u, err := url.Parse(f.url); err == nil {
+			for _, c := range calendars {
+				if samePath(c.Path, u.Path) {
+					return []string{c.Path}, nil
+				}
+			}
+		}
+		for _, c := range calendars {
+			if holds(c, f.component) {
+				return []string{c.Path}, nil
+			}
+		}
+		return nil, fmt.Errorf("no calendars with %s found (available: %s)", f.component, calendarNames(calendars))
+	}
+
+	paths := make([]string, 0, len(f.selected))
+	for _, name := range f.selected {
+		found := false
+		for _, c := range calendars {
+			if strings.EqualFold(c.Name, name) || samePath(c.Path, name) {
+				paths = append(paths, c.Path)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("calendar %q not found (available: %s)", name, calendarNames(calendars))
+		}
+	}
+	return paths, nil
+}
+
+// holds reports whether a calendar supports the component type comp.
+// Generated birthday calendars, as Nextcloud creates from contacts, are
+// skipped.
+func holds(c caldav.Calendar, comp string) bool {
+	if strings.Contains(c.Path, "contact_birthdays") {
+		return false
+	}
+	if len(c.SupportedComponentSet) == 0 {
+		return true
+	}
+	for _, supported := range c.SupportedComponentSet {
+		if strings.EqualFold(supported, comp) {
+			return true
+		}
+	}
+	return false
+}
+
+// samePath compares a calendar path with a configured path or URL.
+func samePath(calendarPath, p string) bool {
+	if u, err := url.Parse(p); err == nil && u.Host != "" {
+		p = u.Path
+	}
+	if p == "" || p == "/" {
+		return false
+	}
+	return strings.TrimSuffix(calendarPath, "/") == strings.TrimSuffix(p, "/")
+}
+
+func calendarNames(calendars []caldav.Calendar) string {
+	names := make([]string, 0, len(calendars))
+	for _, c := range calendars {
+		name := c.Name
+		if name == "" {
+			name = c.Path
+		}
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	return strings.Join(names, ", ")
+}
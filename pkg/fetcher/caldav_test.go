@@ -1,7 +1,17 @@
 package fetcher
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav/caldav"
 )
 
 func TestCalDAVFetcher_Name(t *testing.T) {
@@ -18,3 +28,169 @@ func TestCalDAVFetcher_SetName(t *testing.T) {
 		t.Errorf("Expected name new, got %s", f.Name())
 	}
 }
+
+// memoryCalDAV is a CalDAV backend with one user, laid out like the account
+// of a Nextcloud user: a generated birthday calendar comes first.
+type memoryCalDAV struct {
+	calendars []caldav.Calendar
+	objects   map[string][]caldav.CalendarObject
+}
+
+func newMemoryCalDAV(t *testing.T) *memoryCalDAV {
+	t.Helper()
+	b := &memoryCalDAV{
+		calendars: []caldav.Calendar{
+			{Path: "/jane/calendars/contact_birthdays/", Name: "Contact birthdays", SupportedComponentSet: []string{"VEVENT"}},
+			{Path: "/jane/calendars/tasks/", Name: "Tasks", SupportedComponentSet: []string{"VTODO"}},
+			{Path: "/jane/calendars/family/", Name: "Family", SupportedComponentSet: []string{"VEVENT"}},
+			{Path: "/jane/calendars/work/", Name: "Work", SupportedComponentSet: []string{"VEVENT"}},
+		},
+		objects: make(map[string][]caldav.CalendarObject),
+	}
+	soon := time.Now().Add(2 * time.Hour).UTC().Format(icalDateTimeUTCFormat)
+	for _, c := range b.calendars {
+		name := strings.TrimSuffix(strings.TrimPrefix(c.Path, "/jane/calendars/"), "/")
+		comp, kind, date := "VEVENT", "event", "DTSTART"
+		if c.SupportedComponentSet[0] == "VTODO" {
+			comp, kind, date = "VTODO", "task", "DUE"
+		}
+		cal, err := parseCalendar(strings.NewReader(fmt.Sprintf("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:test\r\n"+
+			"BEGIN:%[1]s\r\nUID:%[2]s\r\nDTSTAMP:20260101T000000Z\r\nSUMMARY:%[3]s %[4]s\r\n%[5]s:%[6]s\r\nEND:%[1]s\r\nEND:VCALENDAR\r\n",
+			comp, name, c.Name, kind, date, soon)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.objects[c.Path] = []caldav.CalendarObject{{Path: c.Path + name + ".ics", Data: cal}}
+	}
+	return b
+}
+
+func (b *memoryCalDAV) CurrentUserPrincipal(ctx context.Context) (string, error) {
+	return "/jane/", nil
+}
+
+func (b *memoryCalDAV) CalendarHomeSetPath(ctx context.Context) (string, error) {
+	return "/jane/calendars/", nil
+}
+
+func (b *memoryCalDAV) CreateCalendar(ctx context.Context, calendar *caldav.Calendar) error {
+	return fmt.Errorf("read-only")
+}
+
+func (b *memoryCalDAV) ListCalendars(ctx context.Context) ([]caldav.Calendar, error) {
+	return b.calendars, nil
+}
+
+func (b *memoryCalDAV) GetCalendar(ctx context.Context, path string) (*caldav.Calendar, error) {
+	for _, c := range b.calendars {
+		if strings.TrimSuffix(c.Path, "/") == strings.TrimSuffix(path, "/") {
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("calendar %s not found", path)
+}
+
+func (b *memoryCalDAV) GetCalendarObject(ctx context.Context, path string, req *caldav.CalendarCompRequest) (*caldav.CalendarObject, error) {
+	return nil, fmt.Errorf("not found")
+}
+
+func (b *memoryCalDAV) ListCalendarObjects(ctx context.Context, path string, req *caldav.CalendarCompRequest) ([]caldav.CalendarObject, error) {
+	return b.objects[strings.TrimSuffix(path, "/")+"/"], nil
+}
+
+func (b *memoryCalDAV) QueryCalendarObjects(ctx context.Context, path string, query *caldav.CalendarQuery) ([]caldav.CalendarObject, error) {
+	return b.objects[strings.TrimSuffix(path, "/")+"/"], nil
+}
+
+func (b *memoryCalDAV) PutCalendarObject(ctx context.Context, path string, calendar *ical.Calendar, opts *caldav.PutCalendarObjectOptions) (*caldav.CalendarObject, error) {
+	return nil, fmt.Errorf("read-only")
+}
+
+func (b *memoryCalDAV) DeleteCalendarObject(ctx context.Context, path string) error {
+	return fmt.Errorf("read-only")
+}
+
+func TestCalDAVFetcher_Discovery(t *testing.T) {
+	handler := &caldav.Handler{Backend: newMemoryCalDAV(t)}
+	var propfinds atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PROPFIND" {
+			propfinds.Add(1)
+			// Only the well-known entry point leads to the principal.
+			if r.URL.Path == "/" || strings.HasPrefix(r.URL.Path, "/dav") {
+				http.NotFound(w, r)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		selected []string
+		want     []string
+		wantErr  string
+	}{
+		{name: "WellKnownDefault", url: server.URL + "/dav/", want: []string{"Family event"}},
+		{name: "CalendarURL", url: server.URL + "/jane/calendars/work/", want: []string{"Work event"}},
+		{name: "ByNameAndPath", url: server.URL + "/dav/", selected: []string{"family", "/jane/calendars/work"}, want: []string{"Family event", "Work event"}},
+		{name: "UnknownName", url: server.URL + "/dav/", selected: []string{"Holidays"}, wantErr: `"Family"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewCalDAVFetcher("cal", tt.url, "jane", "secret")
+			f.SetCalendars(tt.selected)
+			data, err := f.Fetch(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error listing %s, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			events := data.(*CalendarData).Events
+			if len(events) != len(tt.want) {
+				t.Fatalf("Expected %d events, got %+v", len(tt.want), events)
+			}
+			for i, want := range tt.want {
+				if events[i].Summary != want {
+					t.Errorf("Event %d = %q, want %q", i, events[i].Summary, want)
+				}
+			}
+		})
+	}
+
+	// Discovery runs once; later fetches only query the calendar.
+	f := NewCalDAVFetcher("cal", server.URL+"/dav/", "jane", "secret")
+	if _, err := f.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	before := propfinds.Load()
+	if _, err := f.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if n := propfinds.Load() - before; n != 0 {
+		t.Errorf("Expected cached discovery, got %d PROPFIND requests", n)
+	}
+}
+
+func TestCalDAVTaskFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(&caldav.Handler{Backend: newMemoryCalDAV(t)})
+	defer server.Close()
+
+	// Without a selection the first calendar holding tasks is used.
+	f := NewCalDAVTaskFetcher("todo", server.URL+"/jane/", "jane", "secret")
+	data, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	tasks := data.(*TaskData).Tasks
+	if len(tasks) != 1 || tasks[0].Summary != "Tasks task" || tasks[0].Due == nil || tasks[0].Calendar != "todo" {
+		t.Errorf("Unexpected tasks: %+v", tasks)
+	}
+}
@@ -0,0 +1,82 @@
+package fetcher
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+const exportProdID = "-//bros_kiosk//Calendar export//EN"
+
+// EncodeICS writes the events of d as an iCalendar feed named name. All
+// events of the calendar window are written, including the ones the section
+// does not show because of max_events, hide_past or hide_all_day. Timed
+// events are written in UTC; all-day events keep their date in loc, the
+// zone they were shown in. stamp is used as the DTSTAMP of every event.
+//
+// Every occurrence becomes an event of its own. Single events keep the UID
+// of their source event; the occurrences of recurring events get the UID
+// with their start appended, and events without a UID one derived from their
+// calendar, title and start. UIDs thus stay the same across refreshes, no
+// matter how many occurrences fall into the window.
+func EncodeICS(w io.Writer, d *CalendarData, name string, loc *time.Location, stamp time.Time) error {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, exportProdID)
+	cal.Props.SetText("X-WR-CALNAME", name)
+	cal.Props.SetText("X-WR-TIMEZONE", loc.String())
+
+	for _, e := range d.AllEvents() {
+		event := ical.NewEvent()
+		event.Props.SetText(ical.PropUID, exportUID(e))
+		event.Props.SetDateTime(ical.PropDateTimeStamp, stamp.UTC())
+		if e.AllDay {
+			event.Props.SetDate(ical.PropDateTimeStart, e.Start.In(loc))
+			if end := e.End.In(loc); end.After(e.Start.In(loc)) {
+				event.Props.SetDate(ical.PropDateTimeEnd, end)
+			}
+		} else {
+			event.Props.SetDateTime(ical.PropDateTimeStart, e.Start.UTC())
+			if e.End.After(e.Start) {
+				event.Props.SetDateTime(ical.PropDateTimeEnd, e.End.UTC())
+			}
+		}
+		event.Props.SetText(ical.PropSummary, e.Summary)
+		if e.Location != "" {
+			event.Props.SetText(ical.PropLocation, e.Location)
+		}
+		if e.Description != "" {
+			event.Props.SetText(ical.PropDescription, e.Description)
+		}
+		if status := strings.ToUpper(e.Status); status == "TENTATIVE" || status == "CONFIRMED" {
+			event.Props.SetText(ical.PropStatus, status)
+		}
+		if e.Calendar != "" {
+			event.Props.SetText(ical.PropCategories, e.Calendar)
+		}
+		cal.Children = append(cal.Children, event.Component)
+	}
+
+	return ical.NewEncoder(w).Encode(cal)
+}
+
+// exportUID returns the UID of an exported occurrence.
+func exportUID(e CalendarEvent) string {
+	start := e.Start.UTC().Format(icalDateTimeUTCFormat)
+	if e.UID == "" {
+		sum := sha1.Sum([]byte(e.Calendar + "\x00" + e.Summary + "\x00" + start))
+		return hex.EncodeToString(sum[:]) + "@bros_kiosk"
+	}
+	if e.recurring {
+		return e.UID + "-" + start
+	}
+	return e.UID
+}
@@ -0,0 +1,122 @@
+package fetcher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+func TestEncodeICS(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	standup := time.Date(2026, 3, 18, 9, 0, 0, 0, berlin)
+	holiday := time.Date(2026, 3, 19, 0, 0, 0, 0, berlin)
+	data := &CalendarData{Events: []CalendarEvent{
+		{UID: "standup", Summary: "Standup", Start: standup, End: standup.Add(15 * time.Minute), Calendar: "Work", Location: "Room 1, 2nd floor", recurring: true},
+		{UID: "standup", Summary: "Standup", Start: standup.AddDate(0, 0, 1), End: standup.AddDate(0, 0, 1).Add(15 * time.Minute), Calendar: "Work", recurring: true},
+		{UID: "holiday", Summary: "Holiday", Start: holiday, End: holiday.AddDate(0, 0, 1), AllDay: true},
+		{Summary: "Dentist", Start: standup.Add(3 * time.Hour), End: standup.Add(4 * time.Hour), Calendar: "Home"},
+	}}
+	stamp := time.Date(2026, 3, 18, 6, 0, 0, 0, time.UTC)
+
+	encode := func() string {
+		var buf bytes.Buffer
+		if err := EncodeICS(&buf, data, "family", berlin, stamp); err != nil {
+			t.Fatalf("EncodeICS failed: %v", err)
+		}
+		return buf.String()
+	}
+	out := encode()
+	if out != encode() {
+		t.Error("Expected the same feed for the same events")
+	}
+
+	cal, err := parseCalendar(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Feed does not parse: %v\n%s", err, out)
+	}
+	if name, _ := cal.Props.Text("X-WR-CALNAME"); name != "family" {
+		t.Errorf("X-WR-CALNAME = %q", name)
+	}
+	events := cal.Events()
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(events))
+	}
+
+	uid := func(i int) string {
+		v, _ := events[i].Props.Text(ical.PropUID)
+		return v
+	}
+	if uid(0) != "standup-20260318T080000Z" || uid(1) != "standup-20260319T080000Z" {
+		t.Errorf("Expected occurrence UIDs, got %q and %q", uid(0), uid(1))
+	}
+	if uid(2) != "holiday" {
+		t.Errorf("Expected the source UID, got %q", uid(2))
+	}
+	if !strings.HasSuffix(uid(3), "@bros_kiosk") {
+		t.Errorf("Expected a derived UID, got %q", uid(3))
+	}
+
+	if start := events[0].Props.Get(ical.PropDateTimeStart); start.Value != "20260318T080000Z" {
+		t.Errorf("Expected a UTC start, got %q", start.Value)
+	}
+	if loc, _ := events[0].Props.Text(ical.PropLocation); loc != "Room 1, 2nd floor" {
+		t.Errorf("Location = %q", loc)
+	}
+	start := events[2].Props.Get(ical.PropDateTimeStart)
+	if start.Value != "20260319" || start.ValueType() != ical.ValueDate {
+		t.Errorf("Expected an all-day date, got %q", start.Value)
+	}
+	if end := events[2].Props.Get(ical.PropDateTimeEnd); end == nil || end.Value != "20260320" {
+		t.Errorf("Expected an exclusive end date, got %+v", end)
+	}
+}
+
+func TestEncodeICS_StableRecurringUIDs(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:yoga\r\nSUMMARY:Yoga\r\nDTSTART:20260316T180000Z\r\nDTEND:20260316T190000Z\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:dentist\r\nSUMMARY:Dentist\r\nDTSTART:20260317T090000Z\r\nDTEND:20260317T100000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	cal, err := parseCalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uids := func(days int) []string {
+		from := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+		data := &CalendarData{Events: expandEvents(cal.Events(), from, from.AddDate(0, 0, days), newZoneResolver(time.UTC))}
+		var buf bytes.Buffer
+		if err := EncodeICS(&buf, data, "family", time.UTC, from); err != nil {
+			t.Fatalf("EncodeICS failed: %v", err)
+		}
+		out, err := parseCalendar(&buf)
+		if err != nil {
+			t.Fatalf("Feed does not parse: %v", err)
+		}
+		var uids []string
+		for _, e := range out.Events() {
+			uid, _ := e.Props.Text(ical.PropUID)
+			uids = append(uids, uid)
+		}
+		return uids
+	}
+
+	one, two := uids(7), uids(14)
+	want := []string{"yoga-20260316T180000Z", "dentist", "yoga-20260323T180000Z"}
+	if len(one) != 2 || len(two) != 3 {
+		t.Fatalf("Unexpected events: %v and %v", one, two)
+	}
+	for i, uid := range two {
+		if uid != want[i] {
+			t.Errorf("UID %d = %q, want %q", i, uid, want[i])
+		}
+	}
+	if one[0] != two[0] || one[1] != two[1] {
+		t.Errorf("UIDs changed with the window: %v vs %v", one, two)
+	}
+}
@@ -1,6 +1,11 @@
 package fetcher
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"bros_kiosk/pkg/textutil"
+)
 
 // CalendarEvent represents a single normalized calendar event.
 type CalendarEvent struct {
@@ -11,10 +16,83 @@ type CalendarEvent struct {
 	Description string    `json:"description"`
 	Status      string    `json:"status"`
 	AllDay      bool      `json:"all_day"`
+	UID         string    `json:"uid,omitempty"`
+	// Calendar and Color identify the source calendar of the event.
+	Calendar string `json:"calendar,omitempty"`
+	Color    string `json:"color,omitempty"`
+	// RemindAt is when the calendar's ReminderRule first alerts of the
+	// event.
+	RemindAt *time.Time `json:"remind_at,omitempty"`
+
+	// class is the CLASS of the event and declinedBy the addresses of the
+	// attendees that declined it; both feed the calendar's EventRules.
+	class      string
+	declinedBy []string
+	// alarms holds how long before the start the event's VALARMs trigger.
+	alarms []time.Duration
+	// recurring marks occurrences of a recurring event, including the ones
+	// changed through a RECURRENCE-ID; they share the UID of their series.
+	recurring bool
 }
 
 // CalendarData represents the collection of events from a calendar source.
 type CalendarData struct {
 	Source string          `json:"source"`
 	Events []CalendarEvent `json:"events"`
+	Days   []CalendarDay   `json:"days,omitempty"`
+	// Sources reports the state of each calendar merged into the data.
+	Sources []CalendarSourceStatus `json:"sources,omitempty"`
+
+	// merged are the events of the whole window, before max_events,
+	// hide_past and hide_all_day cut them down for display.
+	merged []CalendarEvent
+}
+
+// AllEvents returns the events of the calendar window before the display
+// limits are applied. Data that no aggregator merged only has its Events.
+func (d *CalendarData) AllEvents() []CalendarEvent {
+	if d.merged != nil {
+		return d.merged
+	}
+	return d.Events
+}
+
+// Degraded names the sources of a merged calendar that failed their last
+// fetch.
+func (d *CalendarData) Degraded() string {
+	return degradedSources(d.Sources)
+}
+
+func degradedSources(sources []CalendarSourceStatus) string {
+	var failed []string
+	for _, s := range sources {
+		if s.State != SourceOK {
+			failed = append(failed, s.Name)
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return "unavailable: " + strings.Join(failed, ", ")
+}
+
+// calendarPalette colors the calendars that have no configured color, in
+// the order they are listed.
+var calendarPalette = []string{"#4a90d9", "#e5734a", "#5cb85c", "#b36ae2", "#e0b84a", "#4ac2c2"}
+
+// tagEvents stamps the name and color of their calendar on events.
+func tagEvents(events []CalendarEvent, name, color string) {
+	for i := range events {
+		events[i].Calendar = name
+		events[i].Color = color
+	}
+}
+
+// cleanDescription converts HTML event descriptions, as written by Outlook
+// and Google Calendar, to plain text. Plain text keeps its line breaks.
+func cleanDescription(s string) string {
+	if !strings.Contains(s, "<") {
+		return strings.TrimSpace(s)
+	}
+	return textutil.HTMLToText(s)
 }
@@ -52,3 +52,19 @@ func TestCalendarDataStructure(t *testing.T) {
 		t.Errorf("Expected 2 events, got %d", len(calData.Events))
 	}
 }
+
+func TestCleanDescription(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Bring snacks\nand drinks ", "Bring snacks\nand drinks"},
+		{"<html><body><p>Join&nbsp;the <b>call</b></p><p>Agenda &amp; notes</p></body></html>", "Join the call\n\nAgenda & notes"},
+	}
+
+	for _, tt := range tests {
+		if got := cleanDescription(tt.in); got != tt.want {
+			t.Errorf("cleanDescription(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-webdav/carddav"
+)
+
+// CardDAVFetcher reads birthdays from the address books of a CardDAV
+// account.
+type CardDAVFetcher struct {
+	name     string
+	url      string
+	username string
+	password string
+
+	// mu guards the discovered client and address book paths, which are
+	// kept between fetches.
+	mu     sync.Mutex
+	client *carddav.Client
+	books  []string
+}
+
+// NewCardDAVFetcher creates a new instance of CardDAVFetcher.
+func NewCardDAVFetcher(name, url, username, password string) *CardDAVFetcher {
+	return &CardDAVFetcher{
+		name:     name,
+		url:      url,
+		username: username,
+		password: password,
+	}
+}
+
+// Name returns the fetcher name.
+func (f *CardDAVFetcher) Name() string {
+	return f.name
+}
+
+// birthdayQuery asks for the cards that have a birthday or anniversary.
+var birthdayQuery = &carddav.AddressBookQuery{
+	DataRequest: carddav.AddressDataRequest{AllProp: true},
+	PropFilters: []carddav.PropFilter{
+		{Name: "BDAY"},
+		{Name: "ANNIVERSARY"},
+		{Name: "X-ANNIVERSARY"},
+	},
+	FilterTest: carddav.FilterAnyOf,
+}
+
+// Fetch queries the contacts with a birthday or anniversary. The address
+// books are discovered on the first fetch and again after a query fails.
+func (f *CardDAVFetcher) Fetch(ctx context.Context) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.client == nil {
+		client, books, err := f.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		f.client, f.books = client, books
+	}
+
+	var dates []contactDate
+	for _, book := range f.books {
+		objs, err := f.client.QueryAddressBook(ctx, book, birthdayQuery)
+		if err != nil {
+			// The address book may have moved or the server changed:
+			// discover again on the next fetch.
+			f.client, f.books = nil, nil
+			return nil, fmt.Errorf("query address book failed at %s: %w", book, err)
+		}
+		for _, obj := range objs {
+			dates = append(dates, cardDates(obj.Card)...)
+		}
+	}
+	return &BirthdayData{Source: f.name, Birthdays: birthdaysOf(dates, f.name)}, nil
+}
+
+// discover finds the address books to query, the same way CalDAV
+// calendars are found: the address book home set of the current user is
+// looked up from the URL, /.well-known/carddav and the server root. When
+// the URL is one of the address books only that one is used, otherwise all
+// of them. If none are listed the URL itself is queried as an address book.
+func (f *CardDAVFetcher) discover(ctx context.Context) (*carddav.Client, []string, error) {
+	hc := f.httpClient()
+
+	for _, endpoint := range davEndpoints(ctx, hc, f.url, "carddav") {
+		client, err := carddav.NewClient(hc, endpoint)
+		if err != nil {
+			continue
+		}
+		home, err := findHomeSet(ctx, client, endpoint, client.FindAddressBookHomeSet)
+		if err != nil {
+			slog.Debug("CardDAV discovery failed", "source", f.name, "endpoint", endpoint, "error", err)
+			continue
+		}
+		books, err := client.FindAddressBooks(ctx, home)
+		if err != nil {
+			slog.Debug("CardDAV discovery failed", "source", f.name, "endpoint", endpoint, "error", err)
+			continue
+		}
+		if len(books) == 0 {
+			continue
+		}
+
+		paths := make([]string, 0, len(books))
+		for _, b := range books {
+			if samePath(b.Path, f.url) {
+				paths = []string{b.Path}
+				break
+			}
+			paths = append(paths, b.Path)
+		}
+		slog.Info("CardDAV address books discovered", "source", f.name, "address_books", paths)
+		return client, paths, nil
+	}
+
+	client, err := carddav.NewClient(hc, f.url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create carddav client: %w", err)
+	}
+	return client, []string{""}, nil
+}
+
+// httpClient returns a client that authenticates with the configured
+// credentials.
+func (f *CardDAVFetcher) httpClient() *http.Client {
+	return &http.Client{
+		Transport: &authTransport{
+			Transport: http.DefaultTransport,
+			Username:  f.username,
+			Password:  f.password,
+		},
+		Timeout: 15 * time.Second,
+	}
+}
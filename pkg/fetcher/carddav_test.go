@@ -0,0 +1,130 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// cardDAVServer answers the discovery and query requests of a CardDAV
+// account with two address books, laid out like Nextcloud.
+func cardDAVServer(t *testing.T, propfinds *atomic.Int32) *httptest.Server {
+	t.Helper()
+	const (
+		principal = "/remote.php/dav/principals/users/jane/"
+		home      = "/remote.php/dav/addressbooks/users/jane/"
+	)
+	cards := map[string]string{
+		home + "contacts/": "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Jane Doe\r\nBDAY:1985-04-12\r\nEND:VCARD\r\n",
+		home + "family/":   "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Grandma &amp; Grandpa\r\nANNIVERSARY:19600519\r\nEND:VCARD\r\n",
+	}
+	multistatus := func(w http.ResponseWriter, responses ...string) {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">%s</d:multistatus>`, strings.Join(responses, ""))
+	}
+	response := func(href, props string) string {
+		return fmt.Sprintf(`<d:response><d:href>%s</d:href><d:propstat><d:prop>%s</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>`+
+			`<d:propstat><d:prop><card:addressbook-home-set/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat></d:response>`, href, props)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "jane" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == "PROPFIND" && r.URL.Path == "/.well-known/carddav":
+			propfinds.Add(1)
+			http.Redirect(w, r, "/remote.php/dav/", http.StatusMovedPermanently)
+		case r.Method == "PROPFIND" && strings.TrimSuffix(r.URL.Path, "/") == "/remote.php/dav":
+			// go-webdav asks for the endpoint without its trailing slash.
+			propfinds.Add(1)
+			multistatus(w, response(r.URL.Path, `<d:resourcetype><d:collection/></d:resourcetype><d:current-user-principal><d:href>`+principal+`</d:href></d:current-user-principal>`))
+		case r.Method == "PROPFIND" && r.URL.Path == principal:
+			propfinds.Add(1)
+			multistatus(w, response(principal, `<card:addressbook-home-set><d:href>`+home+`</d:href></card:addressbook-home-set>`))
+		case r.Method == "PROPFIND" && r.URL.Path == home && r.Header.Get("Depth") == "1":
+			propfinds.Add(1)
+			multistatus(w,
+				response(home, `<d:resourcetype><d:collection/></d:resourcetype>`),
+				response(home+"contacts/", `<d:resourcetype><d:collection/><card:addressbook/></d:resourcetype>`),
+				response(home+"family/", `<d:resourcetype><d:collection/><card:addressbook/></d:resourcetype>`))
+		case r.Method == "PROPFIND" && cards[r.URL.Path] != "":
+			propfinds.Add(1)
+			multistatus(w, response(r.URL.Path, `<d:resourcetype><d:collection/><card:addressbook/></d:resourcetype>`))
+		case r.Method == "REPORT" && cards[r.URL.Path] != "":
+			if !strings.Contains(string(body), "addressbook-query") {
+				http.Error(w, "unsupported report", http.StatusBadRequest)
+				return
+			}
+			multistatus(w, response(r.URL.Path+"card.vcf", `<card:address-data>`+cards[r.URL.Path]+`</card:address-data>`))
+		case r.Method == "PROPFIND":
+			propfinds.Add(1)
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCardDAVFetcher_Fetch(t *testing.T) {
+	var propfinds atomic.Int32
+	server := cardDAVServer(t, &propfinds)
+
+	tests := []struct {
+		name    string
+		url     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "WellKnown", url: server.URL + "/", want: []string{"Grandma & Grandpa", "Jane Doe"}},
+		{name: "AddressBookURL", url: server.URL + "/remote.php/dav/addressbooks/users/jane/family/", want: []string{"Grandma & Grandpa"}},
+		{name: "NoAddressBooks", url: "http://127.0.0.1:1/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewCardDAVFetcher("Contacts", tt.url, "jane", "secret").Fetch(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			var names []string
+			for _, b := range data.(*BirthdayData).Birthdays {
+				names = append(names, b.Name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Names = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// Discovery runs once; later fetches only query the address books.
+	f := NewCardDAVFetcher("Contacts", server.URL+"/", "jane", "secret")
+	if _, err := f.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	before := propfinds.Load()
+	if _, err := f.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if n := propfinds.Load() - before; n != 0 {
+		t.Errorf("Expected cached discovery, got %d PROPFIND requests", n)
+	}
+}
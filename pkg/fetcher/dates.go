@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"strings"
+	"time"
+)
+
+// feedDateLayouts are tried in order after the weekday has been stripped.
+var feedDateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04:05 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04 MST",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"Jan _2 15:04:05 2006",
+	"Jan _2 15:04:05 MST 2006",
+	"January 2, 2006 15:04:05 MST",
+	"January 2, 2006",
+	"Jan 2, 2006",
+}
+
+// zoneOffsets resolves zone abbreviations that time.Parse does not know.
+// Parsing "EST" with the MST layout otherwise yields a zero offset.
+var zoneOffsets = map[string]int{
+	"UT":   0,
+	"GMT":  0,
+	"UTC":  0,
+	"Z":    0,
+	"EST":  -5 * 3600,
+	"EDT":  -4 * 3600,
+	"CST":  -6 * 3600,
+	"CDT":  -5 * 3600,
+	"MST":  -7 * 3600,
+	"MDT":  -6 * 3600,
+	"PST":  -8 * 3600,
+	"PDT":  -7 * 3600,
+	"BST":  1 * 3600,
+	"CET":  1 * 3600,
+	"CEST": 2 * 3600,
+	"EET":  2 * 3600,
+	"EEST": 3 * 3600,
+	"JST":  9 * 3600,
+	"AEST": 10 * 3600,
+	"AEDT": 11 * 3600,
+}
+
+// ParseFeedDate parses the publish date of a feed item. It accepts the RFC 822
+// family used by RSS (with or without weekday, numeric or named zones), RFC 3339
+// used by Atom and Dublin Core, and a few common sloppy variants. The second
+// result is false when the date is missing or unrecognized.
+func ParseFeedDate(s string) (time.Time, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return time.Time{}, false
+	}
+
+	// Weekdays are often misspelled ("Thurs,") and carry no information.
+	if i := strings.Index(s, ","); i > 0 && i <= 10 && !strings.ContainsAny(s[:i], "0123456789") {
+		s = strings.TrimSpace(s[i+1:])
+	}
+
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return fixZone(t), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// fixZone applies the offset of a known zone abbreviation when time.Parse
+// could not resolve it.
+func fixZone(t time.Time) time.Time {
+	name, offset := t.Zone()
+	if offset != 0 {
+		return t
+	}
+	if known, ok := zoneOffsets[strings.ToUpper(name)]; ok && known != 0 {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.FixedZone(name, known))
+	}
+	return t
+}
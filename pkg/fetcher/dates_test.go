@@ -0,0 +1,51 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFeedDate(t *testing.T) {
+	want := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"RFC1123Z", "Tue, 05 Mar 2024 15:30:00 +0100"},
+		{"RFC1123", "Tue, 05 Mar 2024 14:30:00 GMT"},
+		{"NamedZone", "Tue, 05 Mar 2024 09:30:00 EST"},
+		{"NoWeekday", "05 Mar 2024 14:30:00 +0000"},
+		{"SingleDigitDay", "Tue, 5 Mar 2024 14:30:00 +0000"},
+		{"MisspelledWeekday", "Tues, 05 Mar 2024 14:30:00 +0000"},
+		{"NoSeconds", "Tue, 05 Mar 2024 14:30 +0000"},
+		{"TwoDigitYear", "Tue, 05 Mar 24 14:30:00 +0000"},
+		{"FullMonth", "Tuesday, 5 March 2024 14:30:00 +0000"},
+		{"ExtraWhitespace", "  Tue,  05 Mar 2024\n14:30:00 +0000 "},
+		{"RFC3339", "2024-03-05T14:30:00Z"},
+		{"RFC3339Offset", "2024-03-05T16:30:00+02:00"},
+		{"RFC3339Fraction", "2024-03-05T14:30:00.000Z"},
+		{"CompactOffset", "2024-03-05T15:30:00+0100"},
+		{"SQL", "2024-03-05 14:30:00 +0000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseFeedDate(tt.input)
+			if !ok {
+				t.Fatalf("Failed to parse %q", tt.input)
+			}
+			if !got.Equal(want) {
+				t.Errorf("Expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestParseFeedDate_Undated(t *testing.T) {
+	for _, input := range []string{"", "   ", "yesterday", "not a date"} {
+		if got, ok := ParseFeedDate(input); ok || !got.IsZero() {
+			t.Errorf("Expected %q to be undated, got %v", input, got)
+		}
+	}
+}
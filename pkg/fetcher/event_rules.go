@@ -0,0 +1,140 @@
+package fetcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Privacy modes for events marked CLASS:PRIVATE or CLASS:CONFIDENTIAL.
+const (
+	PrivacyShow = "show"
+	PrivacyBusy = "busy"
+	PrivacyHide = "hide"
+)
+
+// busyTitle replaces the title of masked private events.
+const busyTitle = "Busy"
+
+// TitleRewrite replaces matches of the Match regex in event titles. Replace
+// may refer to submatches as $1 or ${name}.
+type TitleRewrite struct {
+	Match   string
+	Replace string
+}
+
+// EventRules are the per-calendar rules applied to fetched events. Cancelled
+// events are always dropped, as are invitations declined by one of the
+// calendar owner's addresses.
+type EventRules struct {
+	privacy   string
+	rewrites  []compiledRewrite
+	attendees map[string]bool
+}
+
+type compiledRewrite struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// NewEventRules compiles the rules of a calendar. An empty privacy mode
+// shows private events as they are.
+func NewEventRules(privacy string, rewrites []TitleRewrite, attendees []string) (*EventRules, error) {
+	switch privacy {
+	case "", PrivacyShow, PrivacyBusy, PrivacyHide:
+	default:
+		return nil, fmt.Errorf("invalid privacy mode %q", privacy)
+	}
+
+	r := &EventRules{privacy: privacy, attendees: make(map[string]bool)}
+	for _, rw := range rewrites {
+		re, err := regexp.Compile(rw.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rewrite regex %q: %w", rw.Match, err)
+		}
+		r.rewrites = append(r.rewrites, compiledRewrite{re: re, replace: rw.Replace})
+	}
+	for _, a := range attendees {
+		if a = normalizeAddress(a); a != "" {
+			r.attendees[a] = true
+		}
+	}
+	return r, nil
+}
+
+// apply filters and rewrites events in place. A nil receiver only drops
+// cancelled events.
+func (r *EventRules) apply(events []CalendarEvent) []CalendarEvent {
+	kept := events[:0]
+	for _, e := range events {
+		if strings.EqualFold(e.Status, "CANCELLED") {
+			continue
+		}
+		if r == nil {
+			kept = append(kept, e)
+			continue
+		}
+		if r.declined(e) {
+			continue
+		}
+
+		for _, rw := range r.rewrites {
+			e.Summary = rw.re.ReplaceAllString(e.Summary, rw.replace)
+		}
+
+		if e.isPrivate() {
+			switch r.privacy {
+			case PrivacyHide:
+				continue
+			case PrivacyBusy:
+				e.Summary = busyTitle
+				e.Location = ""
+				e.Description = ""
+			}
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+func (r *EventRules) declined(e CalendarEvent) bool {
+	for _, a := range e.declinedBy {
+		if r.attendees[a] {
+			return true
+		}
+	}
+	return false
+}
+
+func (e CalendarEvent) isPrivate() bool {
+	return strings.EqualFold(e.class, "PRIVATE") || strings.EqualFold(e.class, "CONFIDENTIAL")
+}
+
+// normalizeAddress reduces a calendar user address such as
+// "mailto:Jane@Example.com" to "jane@example.com".
+func normalizeAddress(a string) string {
+	a = strings.TrimSpace(a)
+	if len(a) >= 7 && strings.EqualFold(a[:7], "mailto:") {
+		a = a[7:]
+	}
+	return strings.ToLower(a)
+}
+
+// dedupeEvents drops events that share their UID and start with an earlier
+// event, so a meeting in several calendars is listed once. Events without a
+// UID are kept.
+func dedupeEvents(events []CalendarEvent) []CalendarEvent {
+	seen := make(map[string]bool)
+	kept := events[:0]
+	for _, e := range events {
+		if e.UID != "" {
+			key := fmt.Sprintf("%s@%d", e.UID, e.Start.Unix())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
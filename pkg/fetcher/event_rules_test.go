@@ -0,0 +1,99 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventRules_Apply(t *testing.T) {
+	from := time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC)
+	events := expandICS(t,
+		"BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Standup\r\nDTSTART:20260323T090000Z\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nUID:b\r\nSUMMARY:Offsite\r\nSTATUS:CANCELLED\r\nDTSTART:20260323T100000Z\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nUID:c\r\nSUMMARY:Vendor pitch\r\nDTSTART:20260323T110000Z\r\n"+
+			"ATTENDEE;PARTSTAT=DECLINED:mailto:Jane@Example.com\r\nATTENDEE;PARTSTAT=ACCEPTED:mailto:bob@example.com\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nUID:d\r\nSUMMARY:Doctor\r\nLOCATION:Clinic\r\nCLASS:PRIVATE\r\nDTSTART:20260323T120000Z\r\nEND:VEVENT\r\n"+
+			"BEGIN:VEVENT\r\nUID:e\r\nSUMMARY:[EXT] Sync with ACME\r\nCLASS:PUBLIC\r\nDTSTART:20260323T130000Z\r\nEND:VEVENT\r\n",
+		from, to)
+
+	rewrites := []TitleRewrite{{Match: `^\[EXT\]\s*`, Replace: ""}, {Match: `Sync with (\w+)`, Replace: "$1 call"}}
+
+	tests := []struct {
+		name    string
+		privacy string
+		want    []string
+	}{
+		{"Show", PrivacyShow, []string{"Standup", "Doctor", "ACME call"}},
+		{"Busy", PrivacyBusy, []string{"Standup", "Busy", "ACME call"}},
+		{"Hide", PrivacyHide, []string{"Standup", "ACME call"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewEventRules(tt.privacy, rewrites, []string{"jane@example.com"})
+			if err != nil {
+				t.Fatalf("NewEventRules() error = %v", err)
+			}
+			got := rules.apply(append([]CalendarEvent(nil), events...))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d events, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, want := range tt.want {
+				if got[i].Summary != want {
+					t.Errorf("Event %d = %q, want %q", i, got[i].Summary, want)
+				}
+				if got[i].Summary == busyTitle && got[i].Location != "" {
+					t.Errorf("Busy event leaks its location %q", got[i].Location)
+				}
+			}
+		})
+	}
+
+	// Without rules only cancelled events are dropped.
+	var none *EventRules
+	if got := none.apply(append([]CalendarEvent(nil), events...)); len(got) != 4 {
+		t.Errorf("Expected 4 events without rules, got %d", len(got))
+	}
+}
+
+func TestEventRules_CancelledInstance(t *testing.T) {
+	from := time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
+	events := expandICS(t, "BEGIN:VEVENT\r\nUID:review\r\nSUMMARY:Review\r\nDTSTART:20260302T100000Z\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:review\r\nRECURRENCE-ID:20260323T100000Z\r\nSTATUS:CANCELLED\r\nSUMMARY:Review\r\nDTSTART:20260323T100000Z\r\nEND:VEVENT\r\n", from, to)
+
+	var rules *EventRules
+	got := rules.apply(events)
+	if len(got) != 1 || !got[0].Start.Equal(time.Date(2026, 3, 30, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected only the March 30 instance, got %+v", got)
+	}
+}
+
+func TestNewEventRules_Invalid(t *testing.T) {
+	if _, err := NewEventRules("secret", nil, nil); err == nil {
+		t.Error("Expected error for unknown privacy mode")
+	}
+	if _, err := NewEventRules("", []TitleRewrite{{Match: "("}}, nil); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestDedupeEvents(t *testing.T) {
+	start := time.Date(2026, 3, 23, 9, 0, 0, 0, time.UTC)
+	events := []CalendarEvent{
+		{UID: "m1", Summary: "Planning", Start: start, Calendar: "Shared"},
+		{UID: "m1", Summary: "Planning", Start: start.Add(24 * time.Hour), Calendar: "Shared"},
+		{UID: "m1", Summary: "Planning", Start: start, Calendar: "Personal"},
+		{Summary: "No UID", Start: start},
+		{Summary: "No UID", Start: start},
+	}
+
+	got := dedupeEvents(events)
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 events, got %d: %+v", len(got), got)
+	}
+	if got[0].Calendar != "Shared" {
+		t.Errorf("Expected the first calendar to win, got %q", got[0].Calendar)
+	}
+}
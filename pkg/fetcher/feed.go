@@ -0,0 +1,335 @@
+package fetcher
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"bros_kiosk/pkg/textutil"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	atomNS = "http://www.w3.org/2005/Atom"
+	rdfNS  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+)
+
+// feedItem is an entry of any supported feed format.
+type feedItem struct {
+	Title     string
+	Link      string
+	GUID      string
+	Date      string
+	Summary   string
+	Image     string
+	Thumbnail string
+}
+
+// rssDocument is an RSS 2.0 feed.
+type rssDocument struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// rdfDocument is an RSS 1.0 feed, where items are siblings of the channel.
+type rdfDocument struct {
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	About        string         `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title        string         `xml:"title"`
+	Link         string         `xml:"link"`
+	GUID         string         `xml:"guid"`
+	PubDate      string         `xml:"pubDate"`
+	DCDate       string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Description  string         `xml:"description"`
+	Enclosures   []rssMedia     `xml:"enclosure"`
+	MediaContent []rssMedia     `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroups  []rssMediaList `xml:"http://search.yahoo.com/mrss/ group"`
+	Thumbnails   []rssMedia     `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type rssMedia struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Medium string `xml:"medium,attr"`
+}
+
+type rssMediaList struct {
+	Content    []rssMedia `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []rssMedia `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type atomDocument struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+// MediaContent must precede Content: encoding/xml assigns an element to the
+// first matching field, and an unqualified "content" matches any namespace.
+type atomEntry struct {
+	Title        atomText       `xml:"title"`
+	Links        []atomLink     `xml:"link"`
+	ID           string         `xml:"id"`
+	Updated      string         `xml:"updated"`
+	Published    string         `xml:"published"`
+	DCDate       string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	MediaContent []rssMedia     `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroups  []rssMediaList `xml:"http://search.yahoo.com/mrss/ group"`
+	Thumbnails   []rssMedia     `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	Summary      atomText       `xml:"summary"`
+	Content      atomText       `xml:"content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// atomText is an Atom text construct. XHTML content is kept as markup so it
+// can be stripped like HTML.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// parseFeed detects the feed format from its root element and returns the
+// entries in document order. Non-UTF-8 documents are transcoded using
+// httpCharset (from the Content-Type header) or the XML declaration.
+func parseFeed(r io.Reader, httpCharset string) ([]feedItem, error) {
+	d, err := newFeedDecoder(r, httpCharset)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("failed to decode feed: empty document")
+		}
+		if err != nil {
+			return nil, d.errorf("feed", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case start.Name.Local == "rss":
+			var doc rssDocument
+			if err := d.DecodeElement(&doc, &start); err != nil {
+				return nil, d.errorf("RSS", err)
+			}
+			return rssItems(doc.Channel.Items), nil
+		case start.Name.Local == "RDF" && start.Name.Space == rdfNS:
+			var doc rdfDocument
+			if err := d.DecodeElement(&doc, &start); err != nil {
+				return nil, d.errorf("RDF", err)
+			}
+			return rssItems(doc.Items), nil
+		case start.Name.Local == "feed" && start.Name.Space == atomNS:
+			var doc atomDocument
+			if err := d.DecodeElement(&doc, &start); err != nil {
+				return nil, d.errorf("Atom", err)
+			}
+			return atomItems(doc.Entries), nil
+		default:
+			return nil, fmt.Errorf("unsupported feed format: root element <%s>", start.Name.Local)
+		}
+	}
+}
+
+// feedDecoder is an XML decoder that remembers which charset it decodes, so
+// errors can name it.
+type feedDecoder struct {
+	*xml.Decoder
+	charset string
+}
+
+// newFeedDecoder transcodes the document to UTF-8. A non-UTF-8 charset in the
+// Content-Type header wins over the XML declaration; a UTF-8 one is often just
+// a server default, so the declaration is honored in that case.
+func newFeedDecoder(r io.Reader, httpCharset string) (*feedDecoder, error) {
+	d := &feedDecoder{charset: "utf-8"}
+
+	if httpCharset != "" && !isUTF8(httpCharset) {
+		enc, err := htmlindex.Get(httpCharset)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported feed charset %q", httpCharset)
+		}
+		d.charset = strings.ToLower(httpCharset)
+		d.Decoder = xml.NewDecoder(enc.NewDecoder().Reader(r))
+		d.Decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+		return d, nil
+	}
+
+	d.Decoder = xml.NewDecoder(r)
+	d.Decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(label)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported charset %q", label)
+		}
+		d.charset = strings.ToLower(label)
+		return enc.NewDecoder().Reader(input), nil
+	}
+	return d, nil
+}
+
+func (d *feedDecoder) errorf(format string, err error) error {
+	return fmt.Errorf("failed to decode %s (charset %s): %w", format, d.charset, err)
+}
+
+func isUTF8(label string) bool {
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case "utf-8", "utf8":
+		return true
+	}
+	return false
+}
+
+func rssItems(raw []rssItem) []feedItem {
+	items := make([]feedItem, len(raw))
+	for i, it := range raw {
+		item := feedItem{
+			Title:   strings.TrimSpace(it.Title),
+			Link:    strings.TrimSpace(it.Link),
+			GUID:    firstNonEmpty(it.GUID, it.About),
+			Date:    firstNonEmpty(it.PubDate, it.DCDate),
+			Summary: it.Description,
+			Image:   firstImage(it.Enclosures, it.MediaContent, it.MediaGroups),
+		}
+		item.Thumbnail = item.thumbnail(it.Thumbnails, it.MediaGroups)
+		items[i] = item
+	}
+	return items
+}
+
+func atomItems(raw []atomEntry) []feedItem {
+	items := make([]feedItem, len(raw))
+	for i, e := range raw {
+		var enclosures []rssMedia
+		for _, l := range e.Links {
+			if l.Rel == "enclosure" {
+				enclosures = append(enclosures, rssMedia{URL: l.Href, Type: l.Type})
+			}
+		}
+
+		item := feedItem{
+			Title:   e.Title.String(),
+			Link:    e.link(),
+			GUID:    strings.TrimSpace(e.ID),
+			Date:    firstNonEmpty(e.Updated, e.Published, e.DCDate),
+			Summary: firstNonEmpty(e.Summary.String(), e.Content.String()),
+			Image:   firstImage(enclosures, e.MediaContent, e.MediaGroups),
+		}
+		item.Thumbnail = item.thumbnail(e.Thumbnails, e.MediaGroups)
+		items[i] = item
+	}
+	return items
+}
+
+// link returns the entry's alternate link, falling back to the first link.
+func (e atomEntry) link() string {
+	for _, l := range e.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(e.Links) > 0 {
+		return e.Links[0].Href
+	}
+	return ""
+}
+
+// thumbnail picks a small preview image: an explicit media:thumbnail, the
+// attached image, or the first <img> of the summary. Relative URLs are
+// resolved against the item link.
+func (it feedItem) thumbnail(thumbs []rssMedia, groups []rssMediaList) string {
+	for _, g := range groups {
+		thumbs = append(thumbs, g.Thumbnails...)
+	}
+
+	src := ""
+	for _, t := range thumbs {
+		if t.URL != "" {
+			src = t.URL
+			break
+		}
+	}
+	if src == "" {
+		src = firstNonEmpty(it.Image, textutil.FirstImageSrc(it.Summary))
+	}
+	if src == "" {
+		return ""
+	}
+
+	ref, err := url.Parse(src)
+	if err != nil {
+		return ""
+	}
+	if base, err := url.Parse(it.Link); err == nil && !ref.IsAbs() {
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return ""
+	}
+	return ref.String()
+}
+
+// firstImage returns the first image among an item's attachments, if any.
+func firstImage(enclosures, content []rssMedia, groups []rssMediaList) string {
+	candidates := append([]rssMedia{}, enclosures...)
+	candidates = append(candidates, content...)
+	for _, g := range groups {
+		candidates = append(candidates, g.Content...)
+		candidates = append(candidates, g.Thumbnails...)
+	}
+
+	for _, m := range candidates {
+		if m.URL != "" && m.isImage() {
+			return m.URL
+		}
+	}
+	return ""
+}
+
+func (m rssMedia) isImage() bool {
+	if m.Medium != "" {
+		return m.Medium == "image"
+	}
+	if m.Type != "" {
+		return strings.HasPrefix(m.Type, "image/")
+	}
+	switch strings.ToLower(path.Ext(strings.SplitN(m.URL, "?", 2)[0])) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return true
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,213 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFeed_Atom(t *testing.T) {
+	atom := `<?xml version="1.0" encoding="utf-8"?>
+	<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+		<title>Releases</title>
+		<entry>
+			<id>tag:github.com,2008:Repository/1/v1.2.0</id>
+			<title type="html">v1.2.0 &amp; friends</title>
+			<link rel="self" href="http://example.com/self"/>
+			<link rel="alternate" type="text/html" href="http://example.com/v1.2.0"/>
+			<updated>2024-03-01T10:00:00Z</updated>
+			<content type="html">&lt;p&gt;Bug fixes&lt;/p&gt;</content>
+			<media:group><media:thumbnail url="http://example.com/thumb.jpg"/></media:group>
+		</entry>
+		<entry>
+			<id>urn:2</id>
+			<title>Second</title>
+			<link href="http://example.com/2"/>
+			<published>2024-02-01T10:00:00Z</published>
+			<summary type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><b>Bold</b> text</div></summary>
+		</entry>
+	</feed>`
+
+	items, err := parseFeed(strings.NewReader(atom), "")
+	if err != nil {
+		t.Fatalf("parseFeed failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.Title != "v1.2.0 & friends" {
+		t.Errorf("Unexpected title %q", first.Title)
+	}
+	if first.Link != "http://example.com/v1.2.0" {
+		t.Errorf("Expected alternate link, got %q", first.Link)
+	}
+	if first.GUID != "tag:github.com,2008:Repository/1/v1.2.0" {
+		t.Errorf("Unexpected GUID %q", first.GUID)
+	}
+	if first.Date != "2024-03-01T10:00:00Z" {
+		t.Errorf("Unexpected date %q", first.Date)
+	}
+	if first.Summary != "<p>Bug fixes</p>" {
+		t.Errorf("Expected content as summary, got %q", first.Summary)
+	}
+	if first.Image != "http://example.com/thumb.jpg" {
+		t.Errorf("Expected thumbnail image, got %q", first.Image)
+	}
+
+	second := items[1]
+	if second.Link != "http://example.com/2" || second.Date != "2024-02-01T10:00:00Z" {
+		t.Errorf("Unexpected second entry: %+v", second)
+	}
+	if !strings.Contains(second.Summary, "<b>Bold</b> text") {
+		t.Errorf("Expected XHTML summary markup, got %q", second.Summary)
+	}
+}
+
+func TestParseFeed_RDF(t *testing.T) {
+	rdf := `<?xml version="1.0"?>
+	<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+		xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+		<channel rdf:about="http://example.com/">
+			<title>Example</title>
+		</channel>
+		<item rdf:about="http://example.com/a">
+			<title>Item A</title>
+			<link>http://example.com/a</link>
+			<description>About A</description>
+			<dc:date>2024-03-01T10:00:00+01:00</dc:date>
+		</item>
+	</rdf:RDF>`
+
+	items, err := parseFeed(strings.NewReader(rdf), "")
+	if err != nil {
+		t.Fatalf("parseFeed failed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	item := items[0]
+	if item.Title != "Item A" || item.Link != "http://example.com/a" || item.Summary != "About A" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item.GUID != "http://example.com/a" {
+		t.Errorf("Expected rdf:about as GUID, got %q", item.GUID)
+	}
+	if item.Date != "2024-03-01T10:00:00+01:00" {
+		t.Errorf("Expected dc:date, got %q", item.Date)
+	}
+}
+
+func TestParseFeed_RSSDublinCore(t *testing.T) {
+	rss := `<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><channel>
+		<item><title>DC</title><guid>id-1</guid><dc:date>2024-03-01T10:00:00Z</dc:date></item>
+	</channel></rss>`
+
+	items, err := parseFeed(strings.NewReader(rss), "")
+	if err != nil {
+		t.Fatalf("parseFeed failed: %v", err)
+	}
+	if len(items) != 1 || items[0].Date != "2024-03-01T10:00:00Z" || items[0].GUID != "id-1" {
+		t.Errorf("Unexpected items: %+v", items)
+	}
+}
+
+func TestParseFeed_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Unknown", `<html><body>Not a feed</body></html>`, "unsupported feed format"},
+		{"Empty", ``, "empty document"},
+		{"Malformed", `<rss><channel><item>`, "failed to decode RSS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFeed(strings.NewReader(tt.input), "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseFeed_Charset(t *testing.T) {
+	// "Café" in ISO-8859-1 and "Новости" in windows-1251.
+	latin1 := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><item><title>Caf\xe9</title></item></channel></rss>"
+	cyrillic := "<rss><channel><item><title>\xcd\xee\xe2\xee\xf1\xf2\xe8</title></item></channel></rss>"
+
+	tests := []struct {
+		name        string
+		input       string
+		httpCharset string
+		want        string
+	}{
+		{"Declaration", latin1, "", "Café"},
+		{"DeclarationWithUTF8Header", latin1, "UTF-8", "Café"},
+		{"Header", cyrillic, "windows-1251", "Новости"},
+		{"HeaderOverridesDeclaration", strings.Replace(latin1, "ISO-8859-1", "UTF-8", 1), "iso-8859-1", "Café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := parseFeed(strings.NewReader(tt.input), tt.httpCharset)
+			if err != nil {
+				t.Fatalf("parseFeed failed: %v", err)
+			}
+			if len(items) != 1 || items[0].Title != tt.want {
+				t.Errorf("Expected title %q, got %+v", tt.want, items)
+			}
+		})
+	}
+}
+
+func TestParseFeed_CharsetErrors(t *testing.T) {
+	_, err := parseFeed(strings.NewReader(`<?xml version="1.0" encoding="x-made-up"?><rss/>`), "")
+	if err == nil || !strings.Contains(err.Error(), "x-made-up") {
+		t.Errorf("Expected error naming the declared charset, got %v", err)
+	}
+
+	_, err = parseFeed(strings.NewReader(`<rss/>`), "x-made-up")
+	if err == nil || !strings.Contains(err.Error(), "x-made-up") {
+		t.Errorf("Expected error naming the header charset, got %v", err)
+	}
+
+	// Latin-1 bytes without any declaration are invalid UTF-8.
+	_, err = parseFeed(strings.NewReader("<rss><channel><item><title>Caf\xe9</title></item></channel></rss>"), "")
+	if err == nil || !strings.Contains(err.Error(), "charset utf-8") {
+		t.Errorf("Expected error naming utf-8, got %v", err)
+	}
+}
+
+func TestParseFeed_Thumbnails(t *testing.T) {
+	rss := `<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel>
+		<item><title>Thumb</title><link>http://example.com/a</link>
+			<media:thumbnail url="http://example.com/small.jpg"/>
+			<media:content url="http://example.com/large.jpg" medium="image"/></item>
+		<item><title>Enclosure</title><enclosure url="http://example.com/e.png" type="image/png"/></item>
+		<item><title>Inline</title><link>http://example.com/news/b.html</link>
+			<description><![CDATA[<p><img src="/img/b.jpg" width="100"> Text</p>]]></description></item>
+		<item><title>Data URI</title><description><![CDATA[<img src="data:image/png;base64,AAAA">]]></description></item>
+		<item><title>None</title></item>
+	</channel></rss>`
+
+	items, err := parseFeed(strings.NewReader(rss), "")
+	if err != nil {
+		t.Fatalf("parseFeed failed: %v", err)
+	}
+
+	expected := []string{
+		"http://example.com/small.jpg",
+		"http://example.com/e.png",
+		"http://example.com/img/b.jpg",
+		"",
+		"",
+	}
+	for i, want := range expected {
+		if items[i].Thumbnail != want {
+			t.Errorf("Item %d: expected thumbnail %q, got %q", i, want, items[i].Thumbnail)
+		}
+	}
+}
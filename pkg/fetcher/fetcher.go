@@ -11,6 +11,20 @@ type Status struct {
 	Error     error     `json:"-"`
 	ErrorMsg  string    `json:"error,omitempty"`
 	IsHealthy bool      `json:"is_healthy"`
+	Filtered  int       `json:"filtered,omitempty"`
+	Degraded  string    `json:"degraded,omitempty"`
+}
+
+// FilteredCounter is implemented by payloads that had items removed by
+// filter rules; the count is reported in the Status.
+type FilteredCounter interface {
+	FilteredCount() int
+}
+
+// DegradedReporter is implemented by payloads merged from several sources
+// when some of them failed; the description is reported in the Status.
+type DegradedReporter interface {
+	Degraded() string
 }
 
 // Fetcher defines the interface that all data sources must implement.
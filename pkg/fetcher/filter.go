@@ -0,0 +1,103 @@
+package fetcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"bros_kiosk/pkg/textutil"
+)
+
+// FeedFilter decides which feed items are shown. An item passes when it
+// matches at least one include rule (if any are set), matches no exclude rule
+// and is not older than MaxAge. Undated items never fail the age check.
+type FeedFilter struct {
+	include      []string
+	exclude      []string
+	includeRegex []*regexp.Regexp
+	excludeRegex []*regexp.Regexp
+	maxAge       time.Duration
+}
+
+// NewFeedFilter compiles a filter. Keywords match whole words of the title or
+// summary, ignoring case and diacritics. Regexes match the title or summary
+// as-is; use (?i) for case-insensitive patterns.
+func NewFeedFilter(include, exclude, includeRegex, excludeRegex []string, maxAge time.Duration) (*FeedFilter, error) {
+	f := &FeedFilter{
+		include: normalizeKeywords(include),
+		exclude: normalizeKeywords(exclude),
+		maxAge:  maxAge,
+	}
+
+	var err error
+	if f.includeRegex, err = compilePatterns(includeRegex); err != nil {
+		return nil, err
+	}
+	if f.excludeRegex, err = compilePatterns(excludeRegex); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func normalizeKeywords(keywords []string) []string {
+	dst := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if n := textutil.NormalizeText(k); n != "" {
+			dst = append(dst, n)
+		}
+	}
+	return dst
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	dst := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter regex %q: %w", p, err)
+		}
+		dst = append(dst, re)
+	}
+	return dst, nil
+}
+
+// Allow reports whether the item passes the filter. A nil filter allows
+// everything.
+func (f *FeedFilter) Allow(item RSSItem, now time.Time) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.maxAge > 0 && !item.Undated && now.Sub(item.PubDate) > f.maxAge {
+		return false
+	}
+
+	words := " " + textutil.NormalizeText(item.Title+" "+item.Summary) + " "
+	if containsKeyword(words, f.exclude) || matchesAny(item, f.excludeRegex) {
+		return false
+	}
+
+	if len(f.include) == 0 && len(f.includeRegex) == 0 {
+		return true
+	}
+	return containsKeyword(words, f.include) || matchesAny(item, f.includeRegex)
+}
+
+func containsKeyword(words string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(words, " "+k+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(item RSSItem, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(item.Title) || re.MatchString(item.Summary) {
+			return true
+		}
+	}
+	return false
+}
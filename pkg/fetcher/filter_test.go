@@ -0,0 +1,105 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFeedFilter_Allow(t *testing.T) {
+	now := time.Now()
+	items := map[string]RSSItem{
+		"match":     {Title: "Arsenal win the derby", Summary: "A late goal", PubDate: now},
+		"other":     {Title: "Chelsea draw again", PubDate: now},
+		"politics":  {Title: "Election results", Summary: "Votes counted", PubDate: now},
+		"substring": {Title: "Award season begins", PubDate: now},
+		"accent":    {Title: "Pokémon event", PubDate: now},
+		"old":       {Title: "Arsenal history", PubDate: now.Add(-72 * time.Hour)},
+		"undated":   {Title: "Arsenal undated", Undated: true},
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		incRe   []string
+		excRe   []string
+		maxAge  time.Duration
+		allowed []string
+	}{
+		{"NoRules", nil, nil, nil, nil, 0, []string{"match", "other", "politics", "substring", "accent", "old", "undated"}},
+		{"Include", []string{"arsenal"}, nil, nil, nil, 0, []string{"match", "old", "undated"}},
+		{"Exclude", nil, []string{"Election", "war"}, nil, nil, 0, []string{"match", "other", "substring", "accent", "old", "undated"}},
+		{"KeywordFoldsDiacritics", []string{"pokemon"}, nil, nil, nil, 0, []string{"accent"}},
+		{"IncludeRegex", nil, nil, []string{`(?i)^chelsea`}, nil, 0, []string{"other"}},
+		{"ExcludeRegexSummary", nil, nil, nil, []string{`late goal`}, 0, []string{"other", "politics", "substring", "accent", "old", "undated"}},
+		{"MaxAge", []string{"arsenal"}, nil, nil, nil, 24 * time.Hour, []string{"match", "undated"}},
+		{"ExcludeWins", []string{"arsenal"}, []string{"history"}, nil, nil, 0, []string{"match", "undated"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFeedFilter(tt.include, tt.exclude, tt.incRe, tt.excRe, tt.maxAge)
+			if err != nil {
+				t.Fatalf("NewFeedFilter failed: %v", err)
+			}
+
+			want := make(map[string]bool)
+			for _, k := range tt.allowed {
+				want[k] = true
+			}
+			for key, item := range items {
+				if got := f.Allow(item, now); got != want[key] {
+					t.Errorf("Allow(%s) = %v, want %v", key, got, want[key])
+				}
+			}
+		})
+	}
+}
+
+func TestFeedFilter_InvalidRegex(t *testing.T) {
+	if _, err := NewFeedFilter(nil, nil, []string{"("}, nil, 0); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestRSSFetcher_FilterBeforeTruncation(t *testing.T) {
+	items := ""
+	for i := 1; i <= 6; i++ {
+		items += fmt.Sprintf("<item><title>Politics %d</title></item><item><title>Sport %d</title></item>", i, i)
+	}
+	server := feedServer(t, items)
+
+	exclude, _ := NewFeedFilter(nil, []string{"politics"}, nil, nil, 0)
+	rf := NewRSSFetcher("news", server.URL)
+	rf.SetFilters(nil, exclude)
+
+	data, err := rf.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	feed := data.(*RSSData)
+
+	if len(feed.Items) != defaultMaxNewsItems {
+		t.Fatalf("Expected %d items after filtering, got %d", defaultMaxNewsItems, len(feed.Items))
+	}
+	for _, item := range feed.Items {
+		if item.Title[:5] != "Sport" {
+			t.Errorf("Unexpected item %q", item.Title)
+		}
+	}
+	// All six politics items count, also the ones past the limit.
+	if feed.Filtered != 6 {
+		t.Errorf("Expected 6 filtered items, got %d", feed.Filtered)
+	}
+
+	agg := NewNewsAggregator("news", []*RSSFetcher{rf}, 3)
+	data, err = agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Aggregator fetch failed: %v", err)
+	}
+	if got := data.(*RSSData).FilteredCount(); got != 6 {
+		t.Errorf("Expected aggregator to report 6 filtered items, got %d", got)
+	}
+}
@@ -3,17 +3,35 @@ package fetcher
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
-	ical "github.com/arran4/golang-ical"
+	"github.com/emersion/go-ical"
 )
 
-// ICalFetcher implements the Fetcher interface for iCal/ICS feeds.
+// ICalFetcher implements the Fetcher interface for iCal/ICS feeds. The URL
+// may be http(s), webcal, a file:// URL or an absolute local path; a local
+// directory is read as all the .ics files in it.
 type ICalFetcher struct {
 	name   string
 	url    string
 	client *http.Client
+	loc    *time.Location
+	days   int
+	color  string
+	rules  *EventRules
+	remind *ReminderRule
+
+	username string
+	password string
+	token    string
+	headers  map[string]string
 }
 
 // NewICalFetcher creates a new instance of ICalFetcher.
@@ -24,6 +42,7 @@ func NewICalFetcher(name, url string) *ICalFetcher {
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		loc: time.Local,
 	}
 }
 
@@ -32,6 +51,50 @@ func (f *ICalFetcher) SetName(name string) {
 	f.name = name
 }
 
+// SetLocation sets the display time zone. Floating times and all-day dates
+// are interpreted in it.
+func (f *ICalFetcher) SetLocation(loc *time.Location) {
+	f.loc = loc
+}
+
+// SetDaysAhead sets how many days after today are fetched.
+func (f *ICalFetcher) SetDaysAhead(days int) {
+	f.days = days
+}
+
+// SetColor sets the color the calendar's events are marked with.
+func (f *ICalFetcher) SetColor(color string) {
+	f.color = color
+}
+
+// SetRules sets the privacy, rewrite and attendee rules applied to the
+// calendar's events.
+func (f *ICalFetcher) SetRules(rules *EventRules) {
+	f.rules = rules
+}
+
+// SetReminders sets when the calendar's events raise an alert.
+func (f *ICalFetcher) SetReminders(rule *ReminderRule) {
+	f.remind = rule
+}
+
+// SetAuth sets the credentials sent with basic authentication.
+func (f *ICalFetcher) SetAuth(username, password string) {
+	f.username = username
+	f.password = password
+}
+
+// SetToken sets a bearer token sent in the Authorization header.
+func (f *ICalFetcher) SetToken(token string) {
+	f.token = token
+}
+
+// SetHeaders sets extra request headers. They override the ones the
+// fetcher sets itself.
+func (f *ICalFetcher) SetHeaders(headers map[string]string) {
+	f.headers = headers
+}
+
 // Name returns the fetcher name.
 func (f *ICalFetcher) Name() string {
 	return f.name
@@ -39,10 +102,45 @@ func (f *ICalFetcher) Name() string {
 
 // Fetch retrieves and parses the iCal feed.
 func (f *ICalFetcher) Fetch(ctx context.Context) (interface{}, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", f.url, nil)
+	cal, err := f.download(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := newZoneResolver(f.loc)
+	zones.add(cal)
+
+	from, to := calendarWindow(time.Now(), f.loc, f.days)
+	events := expandEvents(cal.Events(), from, to, zones)
+	events = f.rules.apply(events)
+	f.remind.apply(events)
+	tagEvents(events, f.name, f.color)
+
+	return &CalendarData{
+		Source: f.name,
+		Events: events,
+	}, nil
+}
+
+// download retrieves and parses the feed.
+func (f *ICalFetcher) download(ctx context.Context) (*ical.Calendar, error) {
+	if path, ok := localPath(f.url); ok {
+		return readLocalCalendar(path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", httpURL(f.url), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	switch {
+	case f.token != "":
+		req.Header.Set("Authorization", "Bearer "+f.token)
+	case f.username != "":
+		req.SetBasicAuth(f.username, f.password)
+	}
+	for key, value := range f.headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := f.client.Do(req)
 	if err != nil {
@@ -54,65 +152,94 @@ func (f *ICalFetcher) Fetch(ctx context.Context) (interface{}, error) {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	cal, err := ical.ParseCalendar(resp.Body)
+	cal, err := parseCalendar(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse calendar: %w", err)
 	}
+	return cal, nil
+}
 
-	now := time.Now()
-	sevenDaysFromNow := now.Add(7 * 24 * time.Hour)
-
-	events := make([]CalendarEvent, 0)
-	for _, vEvent := range cal.Events() {
-		event := CalendarEvent{}
-
-		if prop := vEvent.GetProperty(ical.ComponentPropertySummary); prop != nil {
-			event.Summary = prop.Value
-		}
-		if prop := vEvent.GetProperty(ical.ComponentPropertyLocation); prop != nil {
-			event.Location = prop.Value
-		}
-		if prop := vEvent.GetProperty(ical.ComponentPropertyDescription); prop != nil {
-			event.Description = prop.Value
-		}
-		if prop := vEvent.GetProperty(ical.ComponentPropertyStatus); prop != nil {
-			event.Status = prop.Value
+// httpURL maps webcal:// and webcals:// subscription links to https.
+func httpURL(raw string) string {
+	for _, scheme := range []string{"webcal://", "webcals://"} {
+		if len(raw) > len(scheme) && strings.EqualFold(raw[:len(scheme)], scheme) {
+			return "https://" + raw[len(scheme):]
 		}
+	}
+	return raw
+}
 
-		if t, err := vEvent.GetStartAt(); err == nil {
-			event.Start = t
-		}
-		if t, err := vEvent.GetEndAt(); err == nil {
-			event.End = t
+// localPath returns the path of a file:// URL or an absolute path.
+func localPath(raw string) (string, bool) {
+	if strings.HasPrefix(strings.ToLower(raw), "file://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", false
 		}
+		return filepath.FromSlash(u.Path), true
+	}
+	if filepath.IsAbs(raw) {
+		return raw, true
+	}
+	return "", false
+}
 
-		// Skip events outside the next 7 days
-		// If Start is not set, we might want to skip it or include it.
-		// Usually we want to skip it if we can't determine when it starts.
-		if event.Start.IsZero() {
-			continue
-		}
+// readLocalCalendar parses a local .ics file, or merges the .ics files of a
+// directory into one calendar. Files of a directory that fail to parse are
+// skipped, but a directory without a readable .ics file is an error.
+func readLocalCalendar(path string) (*ical.Calendar, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	if !info.IsDir() {
+		return readCalendarFile(path)
+	}
 
-		// If the event ended more than an hour ago, skip it
-		if !event.End.IsZero() && event.End.Before(now.Add(-1*time.Hour)) {
-			continue
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar directory: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".ics") {
+			files = append(files, filepath.Join(path, e.Name()))
 		}
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .ics files in %s", path)
+	}
 
-		// If the event starts more than 7 days from now, skip it
-		if event.Start.After(sevenDaysFromNow) {
+	merged := ical.NewCalendar()
+	read := 0
+	var lastErr error
+	for _, file := range files {
+		cal, err := readCalendarFile(file)
+		if err != nil {
+			slog.Warn("Skipping calendar file", "path", file, "error", err)
+			lastErr = err
 			continue
 		}
+		merged.Children = append(merged.Children, cal.Children...)
+		read++
+	}
+	if read == 0 {
+		return nil, lastErr
+	}
+	return merged, nil
+}
 
-		// Simple all-day detection: if GetAllDayStartAt doesn't error
-		if _, err := vEvent.GetAllDayStartAt(); err == nil {
-			event.AllDay = true
-		}
-
-		events = append(events, event)
+func readCalendarFile(path string) (*ical.Calendar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
 	}
+	defer file.Close()
 
-	return &CalendarData{
-		Source: f.name,
-		Events: events,
-	}, nil
-}
\ No newline at end of file
+	cal, err := parseCalendar(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse calendar %s: %w", filepath.Base(path), err)
+	}
+	return cal, nil
+}
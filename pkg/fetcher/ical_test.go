@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -142,3 +146,123 @@ func TestICalFetcher_FetchErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestICalFetcher_Auth(t *testing.T) {
+	soon := time.Now().Add(time.Hour).UTC().Format(icalDateTimeUTCFormat)
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Private\r\nDTSTART:" + soon + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, basic := r.BasicAuth()
+		authorized := (basic && user == "jane" && pass == "secret") || r.Header.Get("Authorization") == "Bearer s3cret"
+		if !authorized || r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(ics))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		setup   func(f *ICalFetcher)
+		wantErr bool
+	}{
+		{name: "Basic", setup: func(f *ICalFetcher) { f.SetAuth("jane", "secret") }},
+		{name: "Bearer", setup: func(f *ICalFetcher) { f.SetToken("s3cret") }},
+		{name: "WrongPassword", setup: func(f *ICalFetcher) { f.SetAuth("jane", "guess") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewICalFetcher("private", server.URL)
+			f.SetHeaders(map[string]string{"X-Api-Key": "key"})
+			tt.setup(f)
+			data, err := f.Fetch(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			if events := data.(*CalendarData).Events; len(events) != 1 {
+				t.Errorf("Expected 1 event, got %+v", events)
+			}
+		})
+	}
+}
+
+func TestICalFetcher_Local(t *testing.T) {
+	soon := time.Now().Add(time.Hour).UTC().Format(icalDateTimeUTCFormat)
+	ics := func(summary string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:" + summary + "\r\nSUMMARY:" + summary + "\r\nDTSTART:" + soon + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"school.ics": ics("School"),
+		"sports.ICS": ics("Sports"),
+		"broken.ics": "not a calendar",
+		"notes.txt":  ics("Notes"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	emptyDir := t.TempDir()
+	brokenDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(brokenDir, "broken.ics"), []byte("not a calendar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Directory", url: dir, want: []string{"School", "Sports"}},
+		{name: "FileURL", url: "file://" + filepath.ToSlash(filepath.Join(dir, "school.ics")), want: []string{"School"}},
+		{name: "Missing", url: filepath.Join(dir, "missing.ics"), wantErr: true},
+		{name: "Broken", url: filepath.Join(dir, "broken.ics"), wantErr: true},
+		{name: "EmptyDirectory", url: emptyDir, wantErr: true},
+		{name: "BrokenDirectory", url: brokenDir, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewICalFetcher("local", tt.url).Fetch(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch failed: %v", err)
+			}
+			var got []string
+			for _, e := range data.(*CalendarData).Events {
+				got = append(got, e.Summary)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPURL(t *testing.T) {
+	tests := map[string]string{
+		"webcal://example.com/cal.ics":  "https://example.com/cal.ics",
+		"WEBCALS://example.com/cal.ics": "https://example.com/cal.ics",
+		"http://example.com/cal.ics":    "http://example.com/cal.ics",
+	}
+	for in, want := range tests {
+		if got := httpURL(in); got != want {
+			t.Errorf("httpURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
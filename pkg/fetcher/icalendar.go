@@ -0,0 +1,234 @@
+package fetcher
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/teambition/rrule-go"
+)
+
+const (
+	icalDateFormat        = "20060102"
+	icalDateTimeFormat    = "20060102T150405"
+	icalDateTimeUTCFormat = "20060102T150405Z"
+)
+
+// parseCalendar decodes an iCalendar document. The decoder panics on some
+// malformed content lines, which is turned into an error.
+func parseCalendar(r io.Reader) (cal *ical.Calendar, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			cal, err = nil, fmt.Errorf("malformed calendar: %v", p)
+		}
+	}()
+	return ical.NewDecoder(r).Decode()
+}
+
+// expandEvents returns the occurrences of events that overlap [from, to),
+// sorted by start. Recurring events are expanded from their RRULE and RDATEs
+// minus their EXDATEs. Instances changed through a RECURRENCE-ID component
+// replace the generated ones. Times are resolved through z.
+func expandEvents(events []ical.Event, from, to time.Time, z *zoneResolver) []CalendarEvent {
+	result := make([]CalendarEvent, 0)
+	overridden := make(map[string]bool)
+	masters := make([]ical.Event, 0, len(events))
+
+	for _, e := range events {
+		rid := e.Props.Get(ical.PropRecurrenceID)
+		if rid == nil {
+			masters = append(masters, e)
+			continue
+		}
+
+		if t, _, err := z.parse(rid.Value, rid.Params.Get(ical.PropTimezoneID)); err == nil {
+			overridden[instanceKey(e, t)] = true
+		}
+		if ev, ok := newCalendarEvent(e, z); ok && ev.overlaps(from, to) {
+			ev.recurring = true
+			result = append(result, ev)
+		}
+	}
+
+	for _, e := range masters {
+		ev, ok := newCalendarEvent(e, z)
+		if !ok {
+			continue
+		}
+		ev.recurring = e.Props.Get(ical.PropRecurrenceRule) != nil || len(e.Props.Values(ical.PropRecurrenceDates)) > 0
+
+		duration := ev.End.Sub(ev.Start)
+		starts, err := recurrenceStarts(e, ev.Start, from.Add(-duration), to, z)
+		if err != nil {
+			// A broken rule still leaves the first instance worth showing.
+			starts = []time.Time{ev.Start}
+		}
+
+		for _, start := range starts {
+			if overridden[instanceKey(e, start)] {
+				continue
+			}
+			if occ := ev.at(start, duration); occ.overlaps(from, to) {
+				result = append(result, occ)
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// recurrenceStarts returns the instance starts of a master event around
+// [after, before]; callers check the exact overlap. Non-recurring events have
+// a single instance.
+func recurrenceStarts(e ical.Event, start, after, before time.Time, z *zoneResolver) ([]time.Time, error) {
+	rule := e.Props.Get(ical.PropRecurrenceRule)
+	rdates := e.Props.Values(ical.PropRecurrenceDates)
+	if rule == nil && len(rdates) == 0 {
+		return []time.Time{start}, nil
+	}
+
+	set := &rrule.Set{}
+	if rule != nil {
+		opt, err := rrule.StrToROptionInLocation(rule.Value, start.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q: %w", rule.Value, err)
+		}
+		opt.Dtstart = start
+		r, err := rrule.NewRRule(*opt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %q: %w", rule.Value, err)
+		}
+		set.RRule(r)
+	}
+	set.DTStart(start)
+	// DTSTART is always the first instance, even if the rule does not match it.
+	set.RDate(start)
+
+	for _, t := range propTimes(rdates, z) {
+		set.RDate(t)
+	}
+
+	// Instances are generated with the offset of DTSTART. In zones only known
+	// from VTIMEZONE rules that offset is fixed, so each instance's wall clock
+	// is resolved again before EXDATEs are applied.
+	tzid := e.Props.Get(ical.PropDateTimeStart).Params.Get(ical.PropTimezoneID)
+	custom := z.isCustom(tzid)
+	excluded := make(map[int64]bool)
+	for _, t := range propTimes(e.Props.Values(ical.PropExceptionDates), z) {
+		excluded[t.Unix()] = true
+	}
+
+	var starts []time.Time
+	for _, t := range set.Between(after.Add(-24*time.Hour), before.Add(24*time.Hour), true) {
+		if custom {
+			t = z.inZone(t, tzid)
+		}
+		if !excluded[t.Unix()] {
+			starts = append(starts, t)
+		}
+	}
+	return starts, nil
+}
+
+// propTimes parses the comma-separated values of RDATE or EXDATE properties.
+// Periods contribute their start. Unparsable values are skipped.
+func propTimes(props []ical.Prop, z *zoneResolver) []time.Time {
+	var times []time.Time
+	for _, p := range props {
+		for _, v := range strings.Split(p.Value, ",") {
+			v, _, _ = strings.Cut(v, "/")
+			if t, _, err := z.parse(v, p.Params.Get(ical.PropTimezoneID)); err == nil {
+				times = append(times, t)
+			}
+		}
+	}
+	return times
+}
+
+func instanceKey(e ical.Event, start time.Time) string {
+	uid := ""
+	if prop := e.Props.Get(ical.PropUID); prop != nil {
+		uid = prop.Value
+	}
+	return fmt.Sprintf("%s@%d", uid, start.Unix())
+}
+
+// newCalendarEvent converts a VEVENT. The second result is false when the
+// event has no usable start.
+func newCalendarEvent(e ical.Event, z *zoneResolver) (CalendarEvent, bool) {
+	event := CalendarEvent{}
+
+	event.Summary, _ = e.Props.Text(ical.PropSummary)
+	event.Location, _ = e.Props.Text(ical.PropLocation)
+	if desc, _ := e.Props.Text(ical.PropDescription); desc != "" {
+		event.Description = cleanDescription(desc)
+	}
+	if prop := e.Props.Get(ical.PropStatus); prop != nil {
+		event.Status = prop.Value
+	}
+	if prop := e.Props.Get(ical.PropUID); prop != nil {
+		event.UID = prop.Value
+	}
+	if prop := e.Props.Get(ical.PropClass); prop != nil {
+		event.class = prop.Value
+	}
+	for _, prop := range e.Props.Values(ical.PropAttendee) {
+		if strings.EqualFold(prop.Params.Get(ical.ParamParticipationStatus), "DECLINED") {
+			event.declinedBy = append(event.declinedBy, normalizeAddress(prop.Value))
+		}
+	}
+
+	dtstart := e.Props.Get(ical.PropDateTimeStart)
+	if dtstart == nil {
+		return event, false
+	}
+	start, allDay, err := z.parse(dtstart.Value, dtstart.Params.Get(ical.PropTimezoneID))
+	if err != nil {
+		return event, false
+	}
+	event.Start = start
+	event.AllDay = allDay
+	event.End = start
+	if allDay {
+		event.End = start.AddDate(0, 0, 1)
+	}
+
+	if prop := e.Props.Get(ical.PropDateTimeEnd); prop != nil {
+		if end, _, err := z.parse(prop.Value, prop.Params.Get(ical.PropTimezoneID)); err == nil && !end.Before(start) {
+			event.End = end
+		}
+	} else if prop := e.Props.Get(ical.PropDuration); prop != nil {
+		if d, err := prop.Duration(); err == nil && d >= 0 {
+			event.End = start.Add(d)
+		}
+	}
+	event.alarms = eventAlarms(e.Component, event.Start, event.End, z)
+
+	return event, true
+}
+
+// at returns the instance of a recurring event starting at start. All-day
+// instances span whole days so they stay aligned across DST changes.
+func (e CalendarEvent) at(start time.Time, duration time.Duration) CalendarEvent {
+	e.Start = start
+	if e.AllDay {
+		e.End = start.AddDate(0, 0, int(math.Round(duration.Hours()/24)))
+	} else {
+		e.End = start.Add(duration)
+	}
+	return e
+}
+
+func (e CalendarEvent) overlaps(from, to time.Time) bool {
+	if !e.Start.Before(to) {
+		return false
+	}
+	return e.End.After(from) || (e.End.Equal(e.Start) && !e.Start.Before(from))
+}
@@ -0,0 +1,134 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func expandICS(t *testing.T, body string, from, to time.Time) []CalendarEvent {
+	t.Helper()
+	cal, err := parseCalendar(strings.NewReader("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + body + "END:VCALENDAR\r\n"))
+	if err != nil {
+		t.Fatalf("parseCalendar failed: %v", err)
+	}
+	zones := newZoneResolver(time.UTC)
+	zones.add(cal)
+	return expandEvents(cal.Events(), from, to, zones)
+}
+
+func TestExpandEvents_Recurrence(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	// Two weeks around the 2026 DST change in Berlin (March 29).
+	from := time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		ics  string
+		want []time.Time
+	}{
+		{
+			name: "WeeklySince2022",
+			ics: "BEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Standup\r\nDTSTART;TZID=Europe/Berlin:20220103T090000\r\nDTEND;TZID=Europe/Berlin:20220103T091500\r\n" +
+				"RRULE:FREQ=WEEKLY;BYDAY=MO\r\nEND:VEVENT\r\n",
+			want: []time.Time{
+				time.Date(2026, 3, 23, 9, 0, 0, 0, berlin),
+				time.Date(2026, 3, 30, 9, 0, 0, 0, berlin),
+			},
+		},
+		{
+			name: "ExDateAndRDate",
+			ics: "BEGIN:VEVENT\r\nUID:gym\r\nSUMMARY:Gym\r\nDTSTART:20260301T180000Z\r\nDURATION:PT1H\r\n" +
+				"RRULE:FREQ=WEEKLY\r\nEXDATE:20260322T180000Z,20260329T180000Z\r\nRDATE:20260325T180000Z\r\nEND:VEVENT\r\n",
+			want: []time.Time{
+				time.Date(2026, 3, 25, 18, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "OverriddenInstance",
+			ics: "BEGIN:VEVENT\r\nUID:review\r\nSUMMARY:Review\r\nDTSTART:20260302T100000Z\r\nRRULE:FREQ=WEEKLY;COUNT=10\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\nUID:review\r\nRECURRENCE-ID:20260323T100000Z\r\nSUMMARY:Review (moved)\r\nDTSTART:20260324T140000Z\r\nEND:VEVENT\r\n",
+			want: []time.Time{
+				time.Date(2026, 3, 24, 14, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 30, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "FinishedSeries",
+			ics:  "BEGIN:VEVENT\r\nUID:old\r\nSUMMARY:Old\r\nDTSTART:20220103T090000Z\r\nRRULE:FREQ=DAILY;UNTIL=20220110T090000Z\r\nEND:VEVENT\r\n",
+			want: nil,
+		},
+		{
+			name: "OngoingAtWindowStart",
+			ics:  "BEGIN:VEVENT\r\nUID:trip\r\nSUMMARY:Trip\r\nDTSTART:20260320T080000Z\r\nDTEND:20260323T080000Z\r\nEND:VEVENT\r\n",
+			want: []time.Time{
+				time.Date(2026, 3, 20, 8, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := expandICS(t, tt.ics, from, to)
+			if len(events) != len(tt.want) {
+				t.Fatalf("Expected %d events, got %d: %+v", len(tt.want), len(events), events)
+			}
+			for i, want := range tt.want {
+				if !events[i].Start.Equal(want) {
+					t.Errorf("Event %d starts at %v, want %v", i, events[i].Start, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandEvents_OverrideDetails(t *testing.T) {
+	from := time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC)
+
+	events := expandICS(t, "BEGIN:VEVENT\r\nUID:review\r\nSUMMARY:Review\r\nDTSTART:20260302T100000Z\r\nDTEND:20260302T110000Z\r\nRRULE:FREQ=WEEKLY\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:review\r\nRECURRENCE-ID:20260323T100000Z\r\nSUMMARY:Review\\, moved\r\nLOCATION:Room B\r\nDTSTART:20260324T140000Z\r\nDTEND:20260324T150000Z\r\nEND:VEVENT\r\n", from, to)
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].Summary != "Review, moved" || events[0].Location != "Room B" {
+		t.Errorf("Expected overridden details, got %+v", events[0])
+	}
+	if d := events[0].End.Sub(events[0].Start); d != time.Hour {
+		t.Errorf("Expected 1h duration, got %v", d)
+	}
+}
+
+func TestExpandEvents_AllDay(t *testing.T) {
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	events := expandICS(t, "BEGIN:VEVENT\r\nUID:bday\r\nSUMMARY:Birthday\r\nDTSTART;VALUE=DATE:19900512\r\nDTEND;VALUE=DATE:19900513\r\nRRULE:FREQ=YEARLY\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:fair\r\nSUMMARY:Fair\r\nDTSTART:20260520\r\nDTEND:20260523\r\nEND:VEVENT\r\n", from, to)
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	bday := events[0]
+	if !bday.AllDay || !bday.Start.Equal(time.Date(2026, 5, 12, 0, 0, 0, 0, time.UTC)) || !bday.End.Equal(time.Date(2026, 5, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected birthday instance: %+v", bday)
+	}
+	fair := events[1]
+	if !fair.AllDay || fair.End.Sub(fair.Start) != 72*time.Hour {
+		t.Errorf("Unexpected multi-day event: %+v", fair)
+	}
+}
+
+func TestParseCalendar_Malformed(t *testing.T) {
+	if _, err := parseCalendar(strings.NewReader("BEGIN:VCALENDAR\r\nDTSTART;TZID=Europe/Berlin\r\n")); err == nil {
+		t.Error("Expected error for malformed calendar")
+	}
+	if _, err := parseCalendar(strings.NewReader("")); err == nil {
+		t.Error("Expected error for empty calendar")
+	}
+}
@@ -81,6 +81,12 @@ func (m *Manager) runFetcher(ctx context.Context, config FetcherConfig) {
 			if err != nil {
 				status.ErrorMsg = err.Error()
 			}
+			if fc, ok := data.(FilteredCounter); ok {
+				status.Filtered = fc.FilteredCount()
+			}
+			if dr, ok := data.(DegradedReporter); ok {
+				status.Degraded = dr.Degraded()
+			}
 
 			// Publish Result
 			m.updates <- Result{
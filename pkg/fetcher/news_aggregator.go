@@ -0,0 +1,172 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"bros_kiosk/pkg/textutil"
+)
+
+// NewsAggregator fetches several feeds concurrently and merges them into a
+// single, deduplicated list ordered by publish date.
+type NewsAggregator struct {
+	name     string
+	feeds    []*RSSFetcher
+	maxItems int
+}
+
+// NewNewsAggregator creates a new NewsAggregator. maxItems <= 0 uses the
+// default of 5 items.
+func NewNewsAggregator(name string, feeds []*RSSFetcher, maxItems int) *NewsAggregator {
+	if maxItems <= 0 {
+		maxItems = defaultMaxNewsItems
+	}
+	for _, f := range feeds {
+		f.SetMaxItems(maxItems)
+	}
+	return &NewsAggregator{
+		name:     name,
+		feeds:    feeds,
+		maxItems: maxItems,
+	}
+}
+
+// Name returns the aggregator name.
+func (a *NewsAggregator) Name() string {
+	return a.name
+}
+
+// Fetch retrieves all feeds and merges their items.
+func (a *NewsAggregator) Fetch(ctx context.Context) (interface{}, error) {
+	type result struct {
+		feed *RSSFetcher
+		data *RSSData
+		err  error
+	}
+
+	results := make([]result, len(a.feeds))
+	var wg sync.WaitGroup
+	for i, f := range a.feeds {
+		wg.Add(1)
+		go func(i int, f *RSSFetcher) {
+			defer wg.Done()
+			data, err := f.Fetch(ctx)
+			rd, _ := data.(*RSSData)
+			results[i] = result{feed: f, data: rd, err: err}
+		}(i, f)
+	}
+	wg.Wait()
+
+	type weighted struct {
+		item   RSSItem
+		weight float64
+	}
+
+	var firstErr error
+	filtered := 0
+	candidates := make([]weighted, 0)
+	for _, res := range results {
+		if res.err != nil {
+			slog.Warn("News feed failed", "section", a.name, "url", res.feed.url, "error", res.err)
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			continue
+		}
+		if res.data == nil {
+			continue
+		}
+		filtered += res.data.Filtered
+		for _, item := range res.data.Items {
+			candidates = append(candidates, weighted{
+				item:   item,
+				weight: res.feed.weight,
+			})
+		}
+	}
+
+	if len(candidates) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Heavier feeds win duplicates and rank as if their items were newer:
+	// an item's age is divided by its feed's weight.
+	now := time.Now()
+	score := func(w weighted) float64 {
+		if w.item.Undated {
+			return math.Inf(-1)
+		}
+		age := now.Sub(w.item.PubDate).Hours()
+		if age < 0 {
+			age = 0
+		}
+		return -age / w.weight
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].weight > candidates[j].weight
+	})
+
+	seen := make(map[string]bool)
+	merged := make([]weighted, 0, len(candidates))
+	for _, c := range candidates {
+		keys := dedupeKeys(c.item)
+		duplicate := false
+		for _, k := range keys {
+			if seen[k] {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		for _, k := range keys {
+			seen[k] = true
+		}
+		merged = append(merged, c)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		si, sj := score(merged[i]), score(merged[j])
+		if si != sj {
+			return si > sj
+		}
+		return merged[i].item.PubDate.After(merged[j].item.PubDate)
+	})
+
+	if len(merged) > a.maxItems {
+		merged = merged[:a.maxItems]
+	}
+
+	items := make([]RSSItem, len(merged))
+	for i, m := range merged {
+		items[i] = m.item
+	}
+
+	return &RSSData{
+		FeedName: a.name,
+		Items:    items,
+		Filtered: filtered,
+	}, nil
+}
+
+// dedupeKeys returns the identities under which an item is considered the
+// same story across feeds.
+func dedupeKeys(item RSSItem) []string {
+	keys := make([]string, 0, 3)
+	if item.GUID != "" {
+		keys = append(keys, "guid:"+item.GUID)
+	}
+	if item.Link != "" {
+		keys = append(keys, "link:"+item.Link)
+	}
+	if title := textutil.NormalizeText(item.Title); title != "" {
+		keys = append(keys, "title:"+title)
+	}
+	return keys
+}
@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func feedServer(t *testing.T, items string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel>%s</channel></rss>`, items)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewsAggregator_MergeAndDedupe(t *testing.T) {
+	now := time.Now()
+	date := func(d time.Duration) string { return now.Add(-d).Format(time.RFC1123Z) }
+
+	bbc := feedServer(t, fmt.Sprintf(`
+		<item><title>Storm hits coast</title><link>http://bbc/storm</link><pubDate>%s</pubDate></item>
+		<item><title>Old news</title><link>http://bbc/old</link><pubDate>%s</pubDate></item>`,
+		date(2*time.Hour), date(48*time.Hour)))
+	local := feedServer(t, fmt.Sprintf(`
+		<item><title>Storm hits coast!</title><link>http://local/storm</link><pubDate>%s</pubDate></item>
+		<item><title>Bakery opens</title><guid>bakery-1</guid><pubDate>%s</pubDate></item>
+		<item><title>Bakery opens today</title><guid>bakery-1</guid><pubDate>%s</pubDate></item>`,
+		date(time.Hour), date(30*time.Minute), date(20*time.Minute)))
+
+	bbcFetcher := NewRSSFetcher("news", bbc.URL)
+	bbcFetcher.SetSource("BBC", 2)
+	localFetcher := NewRSSFetcher("news", local.URL)
+	localFetcher.SetSource("Local", 1)
+
+	agg := NewNewsAggregator("news", []*RSSFetcher{bbcFetcher, localFetcher}, 3)
+	if agg.Name() != "news" {
+		t.Errorf("Expected name news, got %s", agg.Name())
+	}
+
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	feed := data.(*RSSData)
+
+	if len(feed.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d: %+v", len(feed.Items), feed.Items)
+	}
+
+	// The storm story appears in both feeds; the heavier BBC copy wins.
+	storms := 0
+	for _, item := range feed.Items {
+		if item.Title == "Storm hits coast" || item.Title == "Storm hits coast!" {
+			storms++
+			if item.Source != "BBC" {
+				t.Errorf("Expected BBC copy of duplicate story, got %s", item.Source)
+			}
+		}
+		if item.Title == "Bakery opens today" {
+			t.Error("Expected GUID duplicate to be dropped")
+		}
+	}
+	if storms != 1 {
+		t.Errorf("Expected storm story once, got %d", storms)
+	}
+	if feed.Items[0].Title != "Bakery opens" {
+		t.Errorf("Expected newest item first, got %q", feed.Items[0].Title)
+	}
+}
+
+func TestNewsAggregator_PartialFailure(t *testing.T) {
+	ok := feedServer(t, `<item><title>Working</title></item>`)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+
+	agg := NewNewsAggregator("news", []*RSSFetcher{NewRSSFetcher("a", broken.URL), NewRSSFetcher("b", ok.URL)}, 0)
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Expected partial success, got %v", err)
+	}
+	if items := data.(*RSSData).Items; len(items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(items))
+	}
+
+	agg = NewNewsAggregator("news", []*RSSFetcher{NewRSSFetcher("a", broken.URL)}, 0)
+	if _, err := agg.Fetch(context.Background()); err == nil {
+		t.Error("Expected error when all feeds fail")
+	}
+}
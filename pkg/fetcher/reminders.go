@@ -0,0 +1,129 @@
+package fetcher
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+// ReminderRule decides when the events of a calendar raise an on-screen
+// alert.
+type ReminderRule struct {
+	// Before alerts this long before timed events start; 0 disables it.
+	Before time.Duration
+	// Alarms honors the VALARMs of the events.
+	Alarms bool
+}
+
+// apply sets when events are first reminded of: the earliest of the rule's
+// lead time and, if enabled, the event's alarms. A nil rule reminds of
+// nothing.
+func (r *ReminderRule) apply(events []CalendarEvent) {
+	if r == nil {
+		return
+	}
+	for i := range events {
+		e := &events[i]
+		var lead time.Duration
+		if r.Before > 0 && !e.AllDay {
+			lead = r.Before
+		}
+		if r.Alarms {
+			for _, a := range e.alarms {
+				if a > lead {
+					lead = a
+				}
+			}
+		}
+		if lead > 0 {
+			at := e.Start.Add(-lead)
+			e.RemindAt = &at
+		}
+	}
+}
+
+// eventAlarms returns how long before start the VALARMs of an event
+// trigger. Triggers may be relative to the start or end, or absolute;
+// alarms after the start are left out as the alert ends when the event
+// starts.
+func eventAlarms(c *ical.Component, start, end time.Time, z *zoneResolver) []time.Duration {
+	var leads []time.Duration
+	for _, child := range c.Children {
+		if child.Name != ical.CompAlarm {
+			continue
+		}
+		trigger := child.Props.Get(ical.PropTrigger)
+		if trigger == nil {
+			continue
+		}
+
+		var lead time.Duration
+		if strings.EqualFold(trigger.Params.Get(ical.ParamValue), "DATE-TIME") {
+			at, _, err := z.parse(trigger.Value, "")
+			if err != nil {
+				continue
+			}
+			lead = start.Sub(at)
+		} else {
+			d, err := trigger.Duration()
+			if err != nil {
+				continue
+			}
+			lead = -d
+			if strings.EqualFold(trigger.Params.Get(ical.ParamRelated), "END") {
+				lead = -(end.Sub(start) + d)
+			}
+		}
+		if lead >= 0 {
+			leads = append(leads, lead)
+		}
+	}
+	return leads
+}
+
+// Alert is an event whose reminder is due.
+type Alert struct {
+	ID       string    `json:"id"`
+	Summary  string    `json:"summary"`
+	Location string    `json:"location,omitempty"`
+	Calendar string    `json:"calendar,omitempty"`
+	Color    string    `json:"color,omitempty"`
+	Start    time.Time `json:"start"`
+	RemindAt time.Time `json:"remind_at"`
+}
+
+// Alerts returns the events that are reminded of at now and have not
+// started yet, the soonest first. Events the section does not show because
+// of its display limits are reminded of as well.
+func (d *CalendarData) Alerts(now time.Time) []Alert {
+	var alerts []Alert
+	for _, e := range d.AllEvents() {
+		if e.RemindAt == nil || now.Before(*e.RemindAt) || !now.Before(e.Start) {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			ID:       alertID(e),
+			Summary:  e.Summary,
+			Location: e.Location,
+			Calendar: e.Calendar,
+			Color:    e.Color,
+			Start:    e.Start,
+			RemindAt: *e.RemindAt,
+		})
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Start.Before(alerts[j].Start)
+	})
+	return alerts
+}
+
+// alertID identifies the reminder of one occurrence of an event. It is safe
+// to use in URL paths.
+func alertID(e CalendarEvent) string {
+	sum := sha1.Sum([]byte(e.Calendar + "\x00" + exportUID(e)))
+	return hex.EncodeToString(sum[:8])
+}
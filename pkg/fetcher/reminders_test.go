@@ -0,0 +1,137 @@
+package fetcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventAlarms(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:school\r\nSUMMARY:School run\r\nDTSTART:20260318T081500Z\r\nDTEND:20260318T084500Z\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:-PT10M\r\nEND:VALARM\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER;RELATED=END:-PT1H\r\nEND:VALARM\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER;VALUE=DATE-TIME:20260318T073000Z\r\nEND:VALARM\r\n" +
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\nTRIGGER:PT5M\r\nEND:VALARM\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+	cal, err := parseCalendar(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, ok := newCalendarEvent(cal.Events()[0], newZoneResolver(time.UTC))
+	if !ok {
+		t.Fatal("Event not converted")
+	}
+
+	// 10 minutes before the start, 1 hour before the end and at 07:30; the
+	// alarm after the start is left out.
+	want := []time.Duration{10 * time.Minute, 30 * time.Minute, 45 * time.Minute}
+	if len(event.alarms) != len(want) {
+		t.Fatalf("Alarms = %v, want %v", event.alarms, want)
+	}
+	for i := range want {
+		if event.alarms[i] != want[i] {
+			t.Errorf("Alarm %d = %v, want %v", i, event.alarms[i], want[i])
+		}
+	}
+}
+
+func TestReminderRule(t *testing.T) {
+	start := time.Date(2026, 3, 18, 8, 15, 0, 0, time.UTC)
+	newEvents := func() []CalendarEvent {
+		return []CalendarEvent{
+			{Summary: "School run", Start: start, End: start.Add(30 * time.Minute), alarms: []time.Duration{45 * time.Minute}},
+			{Summary: "Dentist", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)},
+			{Summary: "Holiday", Start: start.Add(-8*time.Hour - 15*time.Minute), End: start.Add(16 * time.Hour), AllDay: true},
+		}
+	}
+
+	tests := []struct {
+		name string
+		rule *ReminderRule
+		want []time.Duration // lead time per event, 0 for none
+	}{
+		{name: "None", rule: nil, want: []time.Duration{0, 0, 0}},
+		{name: "Before", rule: &ReminderRule{Before: 15 * time.Minute}, want: []time.Duration{15 * time.Minute, 15 * time.Minute, 0}},
+		{name: "BeforeAndAlarms", rule: &ReminderRule{Before: 15 * time.Minute, Alarms: true}, want: []time.Duration{45 * time.Minute, 15 * time.Minute, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := newEvents()
+			tt.rule.apply(events)
+			for i, e := range events {
+				switch {
+				case tt.want[i] == 0 && e.RemindAt != nil:
+					t.Errorf("%s: expected no reminder, got %v", e.Summary, e.RemindAt)
+				case tt.want[i] != 0 && (e.RemindAt == nil || !e.RemindAt.Equal(e.Start.Add(-tt.want[i]))):
+					t.Errorf("%s: RemindAt = %v, want %v before start", e.Summary, e.RemindAt, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarData_Alerts(t *testing.T) {
+	start := time.Date(2026, 3, 18, 8, 15, 0, 0, time.UTC)
+	remind := start.Add(-15 * time.Minute)
+	later := start.Add(time.Hour)
+	laterRemind := later.Add(-90 * time.Minute)
+	data := &CalendarData{Events: []CalendarEvent{
+		{UID: "standup", Summary: "Standup", Start: later, End: later.Add(15 * time.Minute), RemindAt: &laterRemind},
+		{UID: "school", Summary: "School run", Start: start, End: start.Add(30 * time.Minute), RemindAt: &remind, Calendar: "Family"},
+		{UID: "lunch", Summary: "Lunch", Start: start.Add(4 * time.Hour), End: start.Add(5 * time.Hour)},
+	}}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{
+		{name: "BeforeReminders", now: start.Add(-time.Hour), want: nil},
+		{name: "Due", now: start.Add(-10 * time.Minute), want: []string{"School run", "Standup"}},
+		{name: "Started", now: start, want: []string{"Standup"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := data.Alerts(tt.now)
+			if len(alerts) != len(tt.want) {
+				t.Fatalf("Expected %d alerts, got %+v", len(tt.want), alerts)
+			}
+			for i, want := range tt.want {
+				if alerts[i].Summary != want {
+					t.Errorf("Alert %d = %q, want %q", i, alerts[i].Summary, want)
+				}
+			}
+		})
+	}
+
+	// IDs are stable and tell occurrences apart.
+	a, b := data.Alerts(start.Add(-time.Minute)), data.Alerts(start.Add(-2*time.Minute))
+	if a[0].ID != b[0].ID || a[0].ID == a[1].ID {
+		t.Errorf("Unexpected alert IDs: %q, %q, %q", a[0].ID, b[0].ID, a[1].ID)
+	}
+}
+
+func TestCalendarAggregator_AlertsBeyondDisplayLimits(t *testing.T) {
+	now := time.Now()
+	first, second := now.Add(30*time.Minute), now.Add(40*time.Minute)
+	remind := now.Add(-time.Minute)
+	cal := &stubCalendar{name: "Work", events: []CalendarEvent{
+		{UID: "first", Summary: "First", Start: first, End: first.Add(time.Hour)},
+		{UID: "second", Summary: "Second", Start: second, End: second.Add(time.Hour), RemindAt: &remind},
+	}}
+	agg := NewCalendarAggregator("cal", []Fetcher{cal})
+	agg.SetOptions(CalendarOptions{MaxEvents: 1})
+
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	alerts := data.(*CalendarData).Alerts(now)
+	if len(alerts) != 1 || alerts[0].Summary != "Second" {
+		t.Errorf("Expected the hidden event's alert, got %+v", alerts)
+	}
+}
@@ -2,41 +2,97 @@ package fetcher
 
 import (
 	"context"
-	"encoding/xml"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"sort"
 	"time"
 
 	"bros_kiosk/pkg/textutil"
 )
 
+const defaultMaxNewsItems = 5
+
 // RSSItem represents a single entry in an RSS feed.
 type RSSItem struct {
-	Title   string `json:"title"`
-	Link    string `json:"link"`
-	PubDate string `json:"pub_date"`
-	Summary string `json:"summary"`
+	Title     string    `json:"title"`
+	Link      string    `json:"link"`
+	GUID      string    `json:"guid,omitempty"`
+	PubDate   time.Time `json:"pub_date"`
+	Undated   bool      `json:"undated,omitempty"`
+	Summary   string    `json:"summary"`
+	Source    string    `json:"source,omitempty"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
 }
 
 // RSSData represents the collection of items from a feed.
 type RSSData struct {
 	FeedName string    `json:"feed_name"`
 	Items    []RSSItem `json:"items"`
+	Filtered int       `json:"filtered,omitempty"`
+}
+
+// FilteredCount returns the number of items removed by filter rules.
+func (d *RSSData) FilteredCount() int {
+	return d.Filtered
+}
+
+// FeedImage is an image attached to a feed item through an enclosure or
+// a Media RSS content element.
+type FeedImage struct {
+	URL     string
+	Title   string
+	PubDate string
+}
+
+// ParseFeedImages extracts the image attachments of a feed in document order.
+// Items without an image enclosure or media:content are skipped. The charset
+// of contentType, the feed's Content-Type header, is honored like in news
+// feeds.
+func ParseFeedImages(r io.Reader, contentType string) ([]FeedImage, error) {
+	items, err := parseFeed(r, contentCharset(contentType))
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]FeedImage, 0, len(items))
+	for _, item := range items {
+		if item.Image == "" {
+			continue
+		}
+		images = append(images, FeedImage{
+			URL:     item.Image,
+			Title:   item.Title,
+			PubDate: item.Date,
+		})
+	}
+	return images, nil
 }
 
 // RSSFetcher implements the Fetcher interface for RSS feeds.
 type RSSFetcher struct {
-	name   string
-	url    string
-	client *http.Client
+	name     string
+	url      string
+	label    string
+	weight   float64
+	maxItems int
+	sumLen   int
+	filters  []*FeedFilter
+	thumbs   bool
+	client   *http.Client
 }
 
 // NewRSSFetcher creates a new instance of RSSFetcher.
 func NewRSSFetcher(name, url string) *RSSFetcher {
 	return &RSSFetcher{
-		name:   name,
-		url:    url,
-		client: &http.Client{Timeout: 10 * time.Second},
+		name:     name,
+		url:      url,
+		weight:   1,
+		maxItems: defaultMaxNewsItems,
+		sumLen:   textutil.DefaultSummaryLength,
+		thumbs:   true,
+		client:   &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
@@ -45,12 +101,71 @@ func (f *RSSFetcher) SetName(name string) {
 	f.name = name
 }
 
+// SetSource sets the label attached to every item and the weight used when
+// merging this feed with others. Non-positive weights default to 1.
+func (f *RSSFetcher) SetSource(label string, weight float64) {
+	if weight <= 0 {
+		weight = 1
+	}
+	f.label = label
+	f.weight = weight
+}
+
+// SetMaxItems limits the number of items returned per fetch.
+func (f *RSSFetcher) SetMaxItems(n int) {
+	if n > 0 {
+		f.maxItems = n
+	}
+}
+
+// SetSummaryLength sets the number of characters summaries are truncated to.
+func (f *RSSFetcher) SetSummaryLength(n int) {
+	if n > 0 {
+		f.sumLen = n
+	}
+}
+
+// SetThumbnails enables or disables thumbnail extraction.
+func (f *RSSFetcher) SetThumbnails(enabled bool) {
+	f.thumbs = enabled
+}
+
+// SetFilters sets the rules items must pass before they count towards the
+// item limit. Nil filters are ignored.
+func (f *RSSFetcher) SetFilters(filters ...*FeedFilter) {
+	f.filters = f.filters[:0]
+	for _, filter := range filters {
+		if filter != nil {
+			f.filters = append(f.filters, filter)
+		}
+	}
+}
+
+func (f *RSSFetcher) allow(item RSSItem, now time.Time) bool {
+	for _, filter := range f.filters {
+		if !filter.Allow(item, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// contentCharset returns the charset parameter of a Content-Type header.
+func contentCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
 // Name returns the fetcher name.
 func (f *RSSFetcher) Name() string {
 	return f.name
 }
 
-// Fetch retrieves and parses the RSS feed.
+// Fetch retrieves and parses the feed. RSS 2.0, RSS 1.0 (RDF) and Atom are
+// supported.
 func (f *RSSFetcher) Fetch(ctx context.Context) (interface{}, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", f.url, nil)
 	if err != nil {
@@ -67,40 +182,80 @@ func (f *RSSFetcher) Fetch(ctx context.Context) (interface{}, error) {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	var rss struct {
-		Channel struct {
-			Items []struct {
-				Title       string `xml:"title"`
-				Link        string `xml:"link"`
-				PubDate     string `xml:"pubDate"`
-				Description string `xml:"description"`
-			} `xml:"item"`
-		} `xml:"channel"`
+	rawItems, err := parseFeed(resp.Body, contentCharset(resp.Header.Get("Content-Type")))
+	if err != nil {
+		return nil, err
 	}
 
-	if err := xml.NewDecoder(resp.Body).Decode(&rss); err != nil {
-		return nil, fmt.Errorf("failed to decode RSS: %w", err)
+	now := time.Now()
+	filtered := 0
+	items := make([]RSSItem, 0, len(rawItems))
+	// Every item goes through the filters so that Filtered counts all the
+	// rejected ones, not just those beyond the limit.
+	for _, raw := range rawItems {
+		pubDate, ok := ParseFeedDate(raw.Date)
+		title := textutil.HTMLToText(raw.Title)
+		item := RSSItem{
+			Title:   title,
+			Link:    raw.Link,
+			GUID:    raw.GUID,
+			PubDate: pubDate,
+			Undated: !ok,
+			Summary: textutil.CleanSummary(raw.Summary, title),
+			Source:  f.label,
+		}
+		if !f.allow(item, now) {
+			filtered++
+			continue
+		}
+		if f.thumbs {
+			item.Thumbnail = raw.Thumbnail
+		}
+		items = append(items, item)
 	}
 
-	rawItems := rss.Channel.Items
-	if len(rawItems) > 5 {
-		rawItems = rawItems[:5]
-	}
-
-	// ... existing code ...
-
-	items := make([]RSSItem, len(rawItems))
-	for i, item := range rawItems {
-		items[i] = RSSItem{
-			Title:   item.Title,
-			Link:    item.Link,
-			PubDate: item.PubDate,
-			Summary: textutil.CleanSummary(item.Description, item.Title),
-		}
+	items = newestItems(items, f.maxItems)
+	for i := range items {
+		items[i].Summary = textutil.Truncate(items[i].Summary, f.sumLen)
 	}
 
 	return &RSSData{
 		FeedName: f.name,
 		Items:    items,
+		Filtered: filtered,
 	}, nil
 }
+
+// newestItems keeps the max newest items, as feeds are not always in date
+// order. Undated items count as the oldest. The kept items stay in feed
+// order, so the first copy of a story repeated within a feed still wins
+// when the news aggregator drops duplicates.
+func newestItems(items []RSSItem, max int) []RSSItem {
+	if len(items) <= max {
+		return items
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := items[order[a]], items[order[b]]
+		if i.Undated != j.Undated {
+			return !i.Undated
+		}
+		return i.PubDate.After(j.PubDate)
+	})
+
+	keep := make([]bool, len(items))
+	for _, i := range order[:max] {
+		keep[i] = true
+	}
+	kept := make([]RSSItem, 0, max)
+	for i, item := range items {
+		if keep[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -54,6 +55,12 @@ func TestRSSFetcher(t *testing.T) {
 	        if feed.Items[0].Title != "Article 1" {
 	                t.Errorf("Expected 'Article 1', got '%s'", feed.Items[0].Title)
 	        }
+	if want := time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC); !feed.Items[0].PubDate.Equal(want) || feed.Items[0].Undated {
+		t.Errorf("Expected parsed date %v, got %v", want, feed.Items[0].PubDate)
+	}
+	if !feed.Items[1].Undated {
+		t.Error("Expected item without pubDate to be flagged undated")
+	}
 	}
 	
 	func TestRSSFetcher_Throttling(t *testing.T) {
@@ -149,3 +156,98 @@ func TestRSSFetcher(t *testing.T) {
 		t.Fatal("Timed out waiting for RSS update via manager")
 	}
 }
+
+func TestParseFeedImages(t *testing.T) {
+	mockRSS := `<?xml version="1.0" encoding="UTF-8" ?>
+	<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/">
+	<channel>
+		<item>
+			<title>Enclosure</title>
+			<enclosure url="http://example.com/1.jpg" type="image/jpeg" length="100"/>
+		</item>
+		<item>
+			<title>Media group</title>
+			<media:group><media:content url="http://example.com/2" medium="image"/></media:group>
+		</item>
+		<item>
+			<title>Podcast</title>
+			<enclosure url="http://example.com/3.mp3" type="audio/mpeg"/>
+		</item>
+		<item>
+			<title>Untyped</title>
+			<media:content url="http://example.com/4.png?size=large"/>
+		</item>
+	</channel>
+	</rss>`
+
+	images, err := ParseFeedImages(strings.NewReader(mockRSS), "")
+	if err != nil {
+		t.Fatalf("ParseFeedImages failed: %v", err)
+	}
+
+	expected := []string{"http://example.com/1.jpg", "http://example.com/2", "http://example.com/4.png?size=large"}
+	if len(images) != len(expected) {
+		t.Fatalf("Expected %d images, got %d: %+v", len(expected), len(images), images)
+	}
+	for i, img := range images {
+		if img.URL != expected[i] {
+			t.Errorf("Index %d: expected %s, got %s", i, expected[i], img.URL)
+		}
+	}
+	if images[1].Title != "Media group" {
+		t.Errorf("Expected title 'Media group', got %q", images[1].Title)
+	}
+}
+
+func TestRSSFetcher_ContentTypeCharset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=windows-1251")
+		w.Write([]byte("<rss><channel><item><title>\xcd\xee\xe2\xee\xf1\xf2\xe8</title></item></channel></rss>"))
+	}))
+	defer server.Close()
+
+	data, err := NewRSSFetcher("rss", server.URL).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if items := data.(*RSSData).Items; len(items) != 1 || items[0].Title != "Новости" {
+		t.Errorf("Expected transcoded title, got %+v", items)
+	}
+}
+
+func TestRSSFetcher_NewestWithinLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<rss><channel>
+			<item><title>Undated</title></item>
+			<item><title>Old</title><pubDate>Mon, 02 Jan 2006 10:00:00 GMT</pubDate></item>
+			<item><title>Newest</title><pubDate>Wed, 04 Jan 2006 10:00:00 GMT</pubDate></item>
+			<item><title>Newer</title><pubDate>Tue, 03 Jan 2006 10:00:00 GMT</pubDate></item>
+		</channel></rss>`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		max  int
+		want []string
+	}{
+		{1, []string{"Newest"}},
+		{2, []string{"Newest", "Newer"}},
+		{3, []string{"Old", "Newest", "Newer"}},
+		{4, []string{"Undated", "Old", "Newest", "Newer"}},
+	}
+	for _, tt := range tests {
+		f := NewRSSFetcher("rss", server.URL)
+		f.SetMaxItems(tt.max)
+		data, err := f.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		var got []string
+		for _, item := range data.(*RSSData).Items {
+			got = append(got, item.Title)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("max %d: got %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
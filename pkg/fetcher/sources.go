@@ -0,0 +1,62 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// sourceResult is the outcome of fetching one source of a merged section.
+type sourceResult struct {
+	data interface{}
+	err  error
+}
+
+// fetchSources fetches all sources concurrently, each bounded by timeout, and
+// reports the state of every source. Data that valid rejects counts as a
+// failure. Failures are logged.
+func fetchSources(ctx context.Context, section string, fetchers []Fetcher, timeout time.Duration, valid func(interface{}) bool) ([]sourceResult, []CalendarSourceStatus) {
+	results := make([]sourceResult, len(fetchers))
+	var wg sync.WaitGroup
+	for i, f := range fetchers {
+		wg.Add(1)
+		go func(i int, f Fetcher) {
+			defer wg.Done()
+			fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			data, err := f.Fetch(fetchCtx)
+			if err == nil && !valid(data) {
+				data, err = nil, fmt.Errorf("unexpected data type %T", data)
+			}
+			results[i] = sourceResult{data: data, err: err}
+		}(i, f)
+	}
+	wg.Wait()
+
+	now := time.Now()
+	sources := make([]CalendarSourceStatus, len(fetchers))
+	for i, res := range results {
+		sources[i] = CalendarSourceStatus{Name: fetchers[i].Name(), State: SourceOK, LastSuccess: now}
+		if res.err != nil {
+			slog.Warn("Source failed", "section", section, "source", sources[i].Name, "error", res.err)
+			sources[i] = CalendarSourceStatus{Name: fetchers[i].Name(), State: SourceError, Error: res.err.Error()}
+		}
+	}
+	return results, sources
+}
+
+// firstError returns the first error of results when every source failed.
+func firstError(results []sourceResult) error {
+	var first error
+	for _, res := range results {
+		if res.err == nil {
+			return nil
+		}
+		if first == nil {
+			first = res.err
+		}
+	}
+	return first
+}
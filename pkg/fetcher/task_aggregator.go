@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+)
+
+// TaskAggregator fetches several task lists concurrently and merges their
+// tasks.
+type TaskAggregator struct {
+	name     string
+	fetchers []Fetcher
+	opts     TaskOptions
+	timeout  time.Duration
+}
+
+// NewTaskAggregator creates a new TaskAggregator.
+func NewTaskAggregator(name string, fetchers []Fetcher) *TaskAggregator {
+	return &TaskAggregator{
+		name:     name,
+		fetchers: fetchers,
+		timeout:  DefaultSourceTimeout,
+	}
+}
+
+// SetOptions sets the limits and time zone applied to the merged tasks.
+func (a *TaskAggregator) SetOptions(opts TaskOptions) {
+	a.opts = opts
+}
+
+// Name returns the aggregator name.
+func (a *TaskAggregator) Name() string {
+	return a.name
+}
+
+// Fetch queries all task lists and merges their tasks. It only fails when
+// every list fails.
+func (a *TaskAggregator) Fetch(ctx context.Context) (interface{}, error) {
+	results, sources := fetchSources(ctx, a.name, a.fetchers, a.timeout, func(data interface{}) bool {
+		td, ok := data.(*TaskData)
+		return ok && td != nil
+	})
+	if err := firstError(results); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0)
+	for i, res := range results {
+		if res.err != nil {
+			continue
+		}
+		td := res.data.(*TaskData)
+		for j := range td.Tasks {
+			if td.Tasks[j].Color == "" {
+				td.Tasks[j].Color = calendarPalette[i%len(calendarPalette)]
+			}
+		}
+		tasks = append(tasks, td.Tasks...)
+	}
+
+	return &TaskData{
+		Source:  a.name,
+		Tasks:   arrangeTasks(tasks, time.Now(), a.opts),
+		Sources: sources,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// stubTasks returns its tasks, or err when set.
+type stubTasks struct {
+	name  string
+	tasks []Task
+	err   error
+}
+
+func (s *stubTasks) Name() string { return s.name }
+
+func (s *stubTasks) Fetch(ctx context.Context) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &TaskData{Source: s.name, Tasks: append([]Task(nil), s.tasks...)}, nil
+}
+
+func TestTaskAggregator_Fetch(t *testing.T) {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	agg := NewTaskAggregator("todo", []Fetcher{
+		&stubTasks{name: "Home", tasks: []Task{{Summary: "Groceries"}, {Summary: "Plants", Due: &tomorrow, Color: "#e33"}}},
+		&stubTasks{name: "Work", err: fmt.Errorf("unauthorized")},
+	})
+	agg.SetOptions(TaskOptions{MaxItems: 5})
+
+	data, err := agg.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	td := data.(*TaskData)
+	if len(td.Tasks) != 2 || td.Tasks[0].Summary != "Plants" || td.Tasks[1].Summary != "Groceries" {
+		t.Fatalf("Unexpected tasks: %+v", td.Tasks)
+	}
+	if td.Tasks[0].Color != "#e33" || td.Tasks[1].Color != calendarPalette[0] {
+		t.Errorf("Unexpected colors: %q, %q", td.Tasks[0].Color, td.Tasks[1].Color)
+	}
+	if len(td.Sources) != 2 || td.Sources[0].State != SourceOK || td.Sources[1].State != SourceError {
+		t.Errorf("Unexpected sources: %+v", td.Sources)
+	}
+	if td.Degraded() == "" {
+		t.Error("Expected degraded status for the failed list")
+	}
+
+	// A source that returns something other than tasks is reported failed.
+	mixed := NewTaskAggregator("todo", []Fetcher{&stubTasks{name: "Home"}, &stubBirthdays{name: "Contacts"}})
+	data, err = mixed.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if sources := data.(*TaskData).Sources; sources[1].State != SourceError || sources[1].Error == "" {
+		t.Errorf("Expected the wrong data type to be an error, got %+v", sources[1])
+	}
+
+	failing := NewTaskAggregator("todo", []Fetcher{&stubTasks{name: "Work", err: fmt.Errorf("unauthorized")}})
+	if _, err := failing.Fetch(context.Background()); err == nil {
+		t.Error("Expected error when every list fails")
+	}
+}
@@ -0,0 +1,272 @@
+package fetcher
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bros_kiosk/pkg/textutil"
+
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav/caldav"
+	"github.com/goodsign/monday"
+)
+
+// Task is a single to-do item.
+type Task struct {
+	UID         string     `json:"uid,omitempty"`
+	Summary     string     `json:"summary"`
+	Description string     `json:"description,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	// AllDay reports a due date without a time.
+	AllDay bool `json:"all_day"`
+	// DueLabel names the due day relative to today, e.g. "Tomorrow".
+	DueLabel string `json:"due_label,omitempty"`
+	// Priority ranges from 1 (highest) to 9 (lowest); 0 is undefined.
+	Priority    int        `json:"priority,omitempty"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Overdue     bool       `json:"overdue"`
+	Calendar    string     `json:"calendar,omitempty"`
+	Color       string     `json:"color,omitempty"`
+}
+
+// TaskData represents the open tasks of a tasks section.
+type TaskData struct {
+	Source string `json:"source"`
+	Tasks  []Task `json:"tasks"`
+	// Sources reports the state of each task list merged into the data.
+	Sources []CalendarSourceStatus `json:"sources,omitempty"`
+}
+
+// Degraded names the task lists that failed their last fetch.
+func (d *TaskData) Degraded() string {
+	return degradedSources(d.Sources)
+}
+
+// TaskOptions controls which tasks a tasks section shows.
+type TaskOptions struct {
+	// MaxItems caps the number of tasks; 0 means no limit.
+	MaxItems int
+	// CompletedGrace is how long completed tasks stay visible.
+	CompletedGrace time.Duration
+	// Location is the display time zone that due dates are compared in.
+	Location *time.Location
+	// Locale is the language of the due labels, such as "de_DE".
+	Locale string
+}
+
+// ICalTaskFetcher reads the tasks (VTODOs) of an iCal feed.
+type ICalTaskFetcher struct {
+	*ICalFetcher
+}
+
+// NewICalTaskFetcher creates a new instance of ICalTaskFetcher.
+func NewICalTaskFetcher(name, url string) *ICalTaskFetcher {
+	return &ICalTaskFetcher{NewICalFetcher(name, url)}
+}
+
+// Fetch retrieves the feed and returns its tasks.
+func (f *ICalTaskFetcher) Fetch(ctx context.Context) (interface{}, error) {
+	cal, err := f.download(ctx)
+	if err != nil {
+		return nil, err
+	}
+	zones := newZoneResolver(f.loc)
+	zones.add(cal)
+
+	tasks := tasksOf([]*ical.Calendar{cal}, zones)
+	tagTasks(tasks, f.name, f.color)
+	return &TaskData{Source: f.name, Tasks: tasks}, nil
+}
+
+// CalDAVTaskFetcher reads the tasks (VTODOs) of CalDAV task lists. Without a
+// selection the first calendar that holds tasks is used.
+type CalDAVTaskFetcher struct {
+	*CalDAVFetcher
+}
+
+// NewCalDAVTaskFetcher creates a new instance of CalDAVTaskFetcher.
+func NewCalDAVTaskFetcher(name, url, username, password string) *CalDAVTaskFetcher {
+	f := NewCalDAVFetcher(name, url, username, password)
+	f.component = ical.CompToDo
+	return &CalDAVTaskFetcher{f}
+}
+
+// Fetch queries the selected task lists.
+func (f *CalDAVTaskFetcher) Fetch(ctx context.Context) (interface{}, error) {
+	cals, err := f.query(ctx, caldav.CompFilter{Name: ical.CompToDo})
+	if err != nil {
+		return nil, err
+	}
+	zones := newZoneResolver(f.loc)
+	for _, cal := range cals {
+		zones.add(cal)
+	}
+
+	tasks := tasksOf(cals, zones)
+	tagTasks(tasks, f.name, f.color)
+	return &TaskData{Source: f.name, Tasks: tasks}, nil
+}
+
+// tasksOf converts the VTODOs of calendars. Cancelled tasks are dropped.
+func tasksOf(cals []*ical.Calendar, z *zoneResolver) []Task {
+	tasks := make([]Task, 0)
+	for _, cal := range cals {
+		for _, child := range cal.Children {
+			if child.Name != ical.CompToDo {
+				continue
+			}
+			if task, ok := newTask(child, z); ok {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks
+}
+
+// newTask converts a VTODO. The second result is false for cancelled tasks.
+func newTask(c *ical.Component, z *zoneResolver) (Task, bool) {
+	task := Task{}
+	task.Summary, _ = c.Props.Text(ical.PropSummary)
+	if desc, _ := c.Props.Text(ical.PropDescription); desc != "" {
+		task.Description = cleanDescription(desc)
+	}
+	if prop := c.Props.Get(ical.PropUID); prop != nil {
+		task.UID = prop.Value
+	}
+	if prop := c.Props.Get(ical.PropPriority); prop != nil {
+		if p, err := strconv.Atoi(strings.TrimSpace(prop.Value)); err == nil && p >= 0 && p <= 9 {
+			task.Priority = p
+		}
+	}
+
+	status := ""
+	if prop := c.Props.Get(ical.PropStatus); prop != nil {
+		status = strings.ToUpper(prop.Value)
+	}
+	if status == "CANCELLED" {
+		return task, false
+	}
+	percent := 0
+	if prop := c.Props.Get(ical.PropPercentComplete); prop != nil {
+		percent, _ = strconv.Atoi(strings.TrimSpace(prop.Value))
+	}
+	completed := c.Props.Get(ical.PropCompleted)
+	task.Completed = status == "COMPLETED" || percent >= 100 || completed != nil
+
+	if task.Completed {
+		// Without a completion time the last change is the best guess.
+		for _, prop := range []*ical.Prop{completed, c.Props.Get(ical.PropLastModified)} {
+			if prop == nil {
+				continue
+			}
+			if t, _, err := z.parse(prop.Value, prop.Params.Get(ical.PropTimezoneID)); err == nil {
+				task.CompletedAt = &t
+				break
+			}
+		}
+	}
+
+	// A task without DUE is due at the end of its DTSTART plus DURATION.
+	if prop := c.Props.Get(ical.PropDue); prop != nil {
+		if t, allDay, err := z.parse(prop.Value, prop.Params.Get(ical.PropTimezoneID)); err == nil {
+			task.Due, task.AllDay = &t, allDay
+		}
+	} else if start := c.Props.Get(ical.PropDateTimeStart); start != nil {
+		if d := c.Props.Get(ical.PropDuration); d != nil {
+			t, allDay, err := z.parse(start.Value, start.Params.Get(ical.PropTimezoneID))
+			dur, derr := d.Duration()
+			if err == nil && derr == nil {
+				t = t.Add(dur)
+				task.Due, task.AllDay = &t, allDay
+			}
+		}
+	}
+	return task, true
+}
+
+// tagTasks stamps the name and color of their task list on tasks.
+func tagTasks(tasks []Task, name, color string) {
+	for i := range tasks {
+		tasks[i].Calendar = name
+		tasks[i].Color = color
+	}
+}
+
+// arrangeTasks drops completed tasks past their grace period, marks overdue
+// tasks and sorts the rest: open tasks by due date, then undated ones by
+// priority, and recently completed tasks last.
+func arrangeTasks(tasks []Task, now time.Time, opts TaskOptions) []Task {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	today := startOfDay(now.In(loc))
+
+	kept := make([]Task, 0, len(tasks))
+	for _, t := range tasks {
+		if t.Completed {
+			if t.CompletedAt == nil || now.Sub(*t.CompletedAt) > opts.CompletedGrace {
+				continue
+			}
+		}
+		if t.Due != nil {
+			due := t.Due.In(loc)
+			day := startOfDay(due)
+			t.DueLabel = dueLabel(day, today, opts.Locale)
+			if !t.Completed {
+				t.Overdue = (t.AllDay && day.Before(today)) || (!t.AllDay && due.Before(now))
+			}
+		}
+		kept = append(kept, t)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		a, b := kept[i], kept[j]
+		if a.Completed != b.Completed {
+			return !a.Completed
+		}
+		if a.Completed {
+			return a.CompletedAt.After(*b.CompletedAt)
+		}
+		if (a.Due == nil) != (b.Due == nil) {
+			return a.Due != nil
+		}
+		if a.Due != nil && !a.Due.Equal(*b.Due) {
+			return a.Due.Before(*b.Due)
+		}
+		if pa, pb := priorityRank(a.Priority), priorityRank(b.Priority); pa != pb {
+			return pa < pb
+		}
+		return strings.ToLower(a.Summary) < strings.ToLower(b.Summary)
+	})
+
+	if opts.MaxItems > 0 && len(kept) > opts.MaxItems {
+		kept = kept[:opts.MaxItems]
+	}
+	return kept
+}
+
+// priorityRank orders priorities with undefined (0) after the lowest (9).
+func priorityRank(p int) int {
+	if p == 0 {
+		return 10
+	}
+	return p
+}
+
+// dueLabel names a due day in the language of locale: "Yesterday",
+// "Today", "Tomorrow", the weekday within the coming week, or the date.
+func dueLabel(day, today time.Time, locale string) string {
+	switch {
+	case day.Equal(today.AddDate(0, 0, -1)):
+		label, _ := textutil.RelativeDay(-1, locale)
+		return label
+	case !day.Before(today) && day.Before(today.AddDate(0, 0, 7)):
+		return dayLabel(day, today, locale)
+	}
+	return monday.Format(day, "Jan 2", textutil.DateLocale(locale))
+}
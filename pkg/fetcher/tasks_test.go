@@ -0,0 +1,144 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestICalTaskFetcher_Fetch(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\nUID:taxes\r\nSUMMARY:File taxes\r\nDUE;VALUE=DATE:20260415\r\nPRIORITY:1\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:plants\r\nSUMMARY:Water plants\r\nDTSTART:20260410T080000Z\r\nDURATION:PT2H\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:car\r\nSUMMARY:Wash car\r\nSTATUS:CANCELLED\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:milk\r\nSUMMARY:Buy milk\r\nPERCENT-COMPLETE:100\r\nLAST-MODIFIED:20260409T120000Z\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:bills\r\nSUMMARY:Pay bills\r\nSTATUS:COMPLETED\r\nCOMPLETED:20260408T090000Z\r\nEND:VTODO\r\n" +
+		"BEGIN:VEVENT\r\nUID:party\r\nSUMMARY:Party\r\nDTSTART:20260410T200000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(ics))
+	}))
+	defer server.Close()
+
+	f := NewICalTaskFetcher("todo", server.URL)
+	f.SetLocation(time.UTC)
+	f.SetColor("#e33")
+	data, err := f.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	tasks := data.(*TaskData).Tasks
+	if len(tasks) != 4 {
+		t.Fatalf("Expected 4 tasks, got %d: %+v", len(tasks), tasks)
+	}
+
+	taxes, plants, milk, bills := tasks[0], tasks[1], tasks[2], tasks[3]
+	if taxes.Summary != "File taxes" || !taxes.AllDay || taxes.Priority != 1 || taxes.Completed ||
+		!taxes.Due.Equal(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected task: %+v", taxes)
+	}
+	if plants.Due == nil || plants.AllDay || !plants.Due.Equal(time.Date(2026, 4, 10, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected due from DTSTART plus DURATION, got %+v", plants)
+	}
+	if !milk.Completed || milk.CompletedAt == nil || !milk.CompletedAt.Equal(time.Date(2026, 4, 9, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected completion from PERCENT-COMPLETE and LAST-MODIFIED, got %+v", milk)
+	}
+	if !bills.Completed || bills.CompletedAt == nil || !bills.CompletedAt.Equal(time.Date(2026, 4, 8, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected completion from COMPLETED, got %+v", bills)
+	}
+	for _, task := range tasks {
+		if task.Calendar != "todo" || task.Color != "#e33" {
+			t.Errorf("Task %q not tagged: %+v", task.Summary, task)
+		}
+	}
+}
+
+func TestArrangeTasks(t *testing.T) {
+	// Wednesday afternoon.
+	now := time.Date(2026, 3, 18, 14, 0, 0, 0, time.UTC)
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	tasks := []Task{
+		{Summary: "Someday"},
+		{Summary: "Urgent someday", Priority: 1},
+		{Summary: "Report", Due: at(18, 9)},
+		{Summary: "Call mom", Due: at(18, 0), AllDay: true},
+		{Summary: "Groceries", Due: at(19, 18)},
+		{Summary: "Taxes", Due: at(17, 0), AllDay: true},
+		{Summary: "Renew passport", Due: at(30, 0), AllDay: true},
+		{Summary: "Done earlier", Completed: true, CompletedAt: at(18, 13)},
+		{Summary: "Done yesterday", Completed: true, CompletedAt: at(17, 9)},
+		{Summary: "Done undated", Completed: true},
+	}
+
+	tests := []struct {
+		name string
+		opts TaskOptions
+		want []string
+	}{
+		{
+			name: "Defaults",
+			opts: TaskOptions{Location: time.UTC},
+			want: []string{"Taxes", "Call mom", "Report", "Groceries", "Renew passport", "Urgent someday", "Someday"},
+		},
+		{
+			name: "CompletedGrace",
+			opts: TaskOptions{Location: time.UTC, CompletedGrace: 2 * time.Hour},
+			want: []string{"Taxes", "Call mom", "Report", "Groceries", "Renew passport", "Urgent someday", "Someday", "Done earlier"},
+		},
+		{
+			name: "MaxItems",
+			opts: TaskOptions{Location: time.UTC, MaxItems: 3},
+			want: []string{"Taxes", "Call mom", "Report"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := arrangeTasks(tasks, now, tt.opts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d tasks, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i, want := range tt.want {
+				if got[i].Summary != want {
+					t.Errorf("Task %d = %q, want %q", i, got[i].Summary, want)
+				}
+			}
+		})
+	}
+
+	got := arrangeTasks(tasks, now, TaskOptions{Location: time.UTC})
+	labels := map[string]string{}
+	overdue := map[string]bool{}
+	for _, task := range got {
+		labels[task.Summary] = task.DueLabel
+		overdue[task.Summary] = task.Overdue
+	}
+	wantLabels := map[string]string{
+		"Taxes": "Yesterday", "Call mom": "Today", "Report": "Today",
+		"Groceries": "Tomorrow", "Renew passport": "Mar 30", "Someday": "",
+	}
+	for summary, want := range wantLabels {
+		if labels[summary] != want {
+			t.Errorf("Label of %q = %q, want %q", summary, labels[summary], want)
+		}
+	}
+	// Labels are written in the configured locale.
+	wantGerman := map[string]string{"Taxes": "Gestern", "Groceries": "Morgen", "Renew passport": "Mär 30"}
+	for _, task := range arrangeTasks(tasks, now, TaskOptions{Location: time.UTC, Locale: "de_DE"}) {
+		if want, ok := wantGerman[task.Summary]; ok && task.DueLabel != want {
+			t.Errorf("German label of %q = %q, want %q", task.Summary, task.DueLabel, want)
+		}
+	}
+	// An all-day task is due until the end of its day.
+	wantOverdue := map[string]bool{"Taxes": true, "Call mom": false, "Report": true, "Groceries": false}
+	for summary, want := range wantOverdue {
+		if overdue[summary] != want {
+			t.Errorf("Overdue of %q = %v, want %v", summary, overdue[summary], want)
+		}
+	}
+}
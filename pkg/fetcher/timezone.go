@@ -0,0 +1,300 @@
+package fetcher
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/teambition/rrule-go"
+)
+
+// windowsZones maps the Windows zone names written by Outlook and Exchange
+// to IANA names.
+var windowsZones = map[string]string{
+	"Dateline Standard Time":          "Etc/GMT+12",
+	"Hawaiian Standard Time":          "Pacific/Honolulu",
+	"Alaskan Standard Time":           "America/Anchorage",
+	"Pacific Standard Time":           "America/Los_Angeles",
+	"US Mountain Standard Time":       "America/Phoenix",
+	"Mountain Standard Time":          "America/Denver",
+	"Central Standard Time":           "America/Chicago",
+	"Eastern Standard Time":           "America/New_York",
+	"Atlantic Standard Time":          "America/Halifax",
+	"Newfoundland Standard Time":      "America/St_Johns",
+	"E. South America Standard Time":  "America/Sao_Paulo",
+	"Argentina Standard Time":         "America/Buenos_Aires",
+	"UTC":                             "UTC",
+	"GMT Standard Time":               "Europe/London",
+	"Greenwich Standard Time":         "Atlantic/Reykjavik",
+	"W. Europe Standard Time":         "Europe/Berlin",
+	"Central Europe Standard Time":    "Europe/Budapest",
+	"Central European Standard Time":  "Europe/Warsaw",
+	"Romance Standard Time":           "Europe/Paris",
+	"E. Europe Standard Time":         "Europe/Chisinau",
+	"GTB Standard Time":               "Europe/Bucharest",
+	"FLE Standard Time":               "Europe/Kiev",
+	"Israel Standard Time":            "Asia/Jerusalem",
+	"Turkey Standard Time":            "Europe/Istanbul",
+	"Russian Standard Time":           "Europe/Moscow",
+	"South Africa Standard Time":      "Africa/Johannesburg",
+	"Arabian Standard Time":           "Asia/Dubai",
+	"India Standard Time":             "Asia/Calcutta",
+	"China Standard Time":             "Asia/Shanghai",
+	"Singapore Standard Time":         "Asia/Singapore",
+	"Tokyo Standard Time":             "Asia/Tokyo",
+	"Korea Standard Time":             "Asia/Seoul",
+	"AUS Eastern Standard Time":       "Australia/Sydney",
+	"E. Australia Standard Time":      "Australia/Brisbane",
+	"Cen. Australia Standard Time":    "Australia/Adelaide",
+	"W. Australia Standard Time":      "Australia/Perth",
+	"New Zealand Standard Time":       "Pacific/Auckland",
+	"Central America Standard Time":   "America/Guatemala",
+	"SA Pacific Standard Time":        "America/Bogota",
+	"Pacific SA Standard Time":        "America/Santiago",
+	"Mountain Standard Time (Mexico)": "America/Chihuahua",
+	"Central Standard Time (Mexico)":  "America/Mexico_City",
+}
+
+// zoneResolver maps the TZIDs of calendar data to time zones. It tries the
+// TZID as an IANA name, the X-LIC-LOCATION of its VTIMEZONE, the Windows zone
+// table and a trailing "Area/City" path. As a last resort the observances of
+// the VTIMEZONE are evaluated directly. Unknown TZIDs are treated as floating
+// times in the display zone.
+type zoneResolver struct {
+	loc       *time.Location
+	defs      map[string]ical.Component
+	locations map[string]*time.Location
+	custom    map[string]*vtimezone
+}
+
+func newZoneResolver(loc *time.Location) *zoneResolver {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &zoneResolver{
+		loc:       loc,
+		defs:      make(map[string]ical.Component),
+		locations: make(map[string]*time.Location),
+		custom:    make(map[string]*vtimezone),
+	}
+}
+
+// add registers the VTIMEZONE definitions of a calendar.
+func (z *zoneResolver) add(cal *ical.Calendar) {
+	for _, child := range cal.Children {
+		if child.Name != ical.CompTimezone {
+			continue
+		}
+		if prop := child.Props.Get(ical.PropTimezoneID); prop != nil {
+			z.defs[prop.Value] = *child
+		}
+	}
+}
+
+// parse reads a DATE or DATE-TIME value. The second result reports a DATE.
+// Dates are floating: they start at midnight in the display zone.
+func (z *zoneResolver) parse(value, tzid string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case len(value) == len(icalDateFormat):
+		t, err := time.ParseInLocation(icalDateFormat, value, z.loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(icalDateTimeUTCFormat, value)
+		return t, false, err
+	}
+
+	if tzid == "" {
+		t, err := time.ParseInLocation(icalDateTimeFormat, value, z.loc)
+		return t, false, err
+	}
+
+	wall, err := time.ParseInLocation(icalDateTimeFormat, value, time.UTC)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return z.inZone(wall, tzid), false, nil
+}
+
+// inZone interprets the wall clock of t (its fields, not its instant) in the
+// zone named by tzid.
+func (z *zoneResolver) inZone(t time.Time, tzid string) time.Time {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	if loc := z.location(tzid); loc != nil {
+		return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+	}
+	if tz := z.custom[tzid]; tz != nil {
+		offset := tz.offsetAt(wall)
+		return wall.Add(-time.Duration(offset) * time.Second).In(time.FixedZone(tzid, offset))
+	}
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), z.loc)
+}
+
+// isCustom reports whether tzid is only known from its VTIMEZONE rules, so
+// times in it carry a fixed offset that must be recomputed per instance.
+func (z *zoneResolver) isCustom(tzid string) bool {
+	if tzid == "" || z.location(tzid) != nil {
+		return false
+	}
+	return z.custom[tzid] != nil
+}
+
+func (z *zoneResolver) location(tzid string) *time.Location {
+	if loc, ok := z.locations[tzid]; ok {
+		return loc
+	}
+
+	var loc *time.Location
+	for _, name := range z.candidates(tzid) {
+		if l, err := time.LoadLocation(name); err == nil && name != "" && name != "Local" {
+			loc = l
+			break
+		}
+	}
+	if loc == nil {
+		if def, ok := z.defs[tzid]; ok {
+			if tz := newVTimezone(def); tz != nil {
+				z.custom[tzid] = tz
+			}
+		}
+	}
+
+	z.locations[tzid] = loc
+	return loc
+}
+
+func (z *zoneResolver) candidates(tzid string) []string {
+	tzid = strings.Trim(tzid, `"`)
+	names := []string{tzid}
+	if def, ok := z.defs[tzid]; ok {
+		if prop := def.Props.Get("X-LIC-LOCATION"); prop != nil {
+			names = append(names, prop.Value)
+		}
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		names = append(names, name)
+	}
+	// Mozilla and others prefix the IANA name: /mozilla.org/20050126_1/Europe/Berlin
+	parts := strings.Split(strings.Trim(tzid, "/"), "/")
+	for n := 3; n >= 2; n-- {
+		if len(parts) > n {
+			names = append(names, strings.Join(parts[len(parts)-n:], "/"))
+		}
+	}
+	return names
+}
+
+// vtimezone evaluates the STANDARD and DAYLIGHT observances of a VTIMEZONE.
+type vtimezone struct {
+	observances []observance
+}
+
+type observance struct {
+	onsets *rrule.Set
+	from   int
+	to     int
+}
+
+func newVTimezone(def ical.Component) *vtimezone {
+	tz := &vtimezone{}
+	for _, child := range def.Children {
+		if child.Name != ical.CompTimezoneStandard && child.Name != ical.CompTimezoneDaylight {
+			continue
+		}
+		o, ok := newObservance(*child)
+		if ok {
+			tz.observances = append(tz.observances, o)
+		}
+	}
+	if len(tz.observances) == 0 {
+		return nil
+	}
+	return tz
+}
+
+// newObservance reads an observance. Onsets are kept as wall clock times in
+// UTC, the same representation offsetAt is called with.
+func newObservance(c ical.Component) (observance, bool) {
+	var o observance
+	var ok bool
+	if o.to, ok = parseUTCOffset(c.Props.Get(ical.PropTimezoneOffsetTo)); !ok {
+		return o, false
+	}
+	if o.from, ok = parseUTCOffset(c.Props.Get(ical.PropTimezoneOffsetFrom)); !ok {
+		o.from = o.to
+	}
+
+	dtstart := c.Props.Get(ical.PropDateTimeStart)
+	if dtstart == nil {
+		return o, false
+	}
+	start, err := time.ParseInLocation(icalDateTimeFormat, strings.TrimSuffix(dtstart.Value, "Z"), time.UTC)
+	if err != nil {
+		return o, false
+	}
+
+	o.onsets = &rrule.Set{}
+	if prop := c.Props.Get(ical.PropRecurrenceRule); prop != nil {
+		if opt, err := rrule.StrToROptionInLocation(prop.Value, time.UTC); err == nil {
+			opt.Dtstart = start
+			if r, err := rrule.NewRRule(*opt); err == nil {
+				o.onsets.RRule(r)
+			}
+		}
+	}
+	o.onsets.DTStart(start)
+	o.onsets.RDate(start)
+	for _, prop := range c.Props.Values(ical.PropRecurrenceDates) {
+		for _, v := range strings.Split(prop.Value, ",") {
+			if t, err := time.ParseInLocation(icalDateTimeFormat, strings.TrimSuffix(v, "Z"), time.UTC); err == nil {
+				o.onsets.RDate(t)
+			}
+		}
+	}
+	return o, true
+}
+
+// offsetAt returns the UTC offset in seconds in effect at the given wall
+// clock time.
+func (tz *vtimezone) offsetAt(wall time.Time) int {
+	var latest time.Time
+	offset := tz.observances[0].from
+	for _, o := range tz.observances {
+		onset := o.onsets.Before(wall, true)
+		if !onset.IsZero() && onset.After(latest) {
+			latest, offset = onset, o.to
+		}
+	}
+	return offset
+}
+
+// parseUTCOffset parses a UTC offset such as +0100 or -053000 into seconds.
+func parseUTCOffset(prop *ical.Prop) (int, bool) {
+	if prop == nil {
+		return 0, false
+	}
+	v := strings.TrimSpace(prop.Value)
+	if len(v) != 5 && len(v) != 7 {
+		return 0, false
+	}
+	sign := 1
+	switch v[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, false
+	}
+	h, err1 := strconv.Atoi(v[1:3])
+	m, err2 := strconv.Atoi(v[3:5])
+	s := 0
+	var err3 error
+	if len(v) == 7 {
+		s, err3 = strconv.Atoi(v[5:7])
+	}
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, false
+	}
+	return sign * (h*3600 + m*60 + s), true
+}
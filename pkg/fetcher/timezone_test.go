@@ -0,0 +1,92 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const customZoneICS = "BEGIN:VTIMEZONE\r\nTZID:Custom Europe\r\n" +
+	"BEGIN:STANDARD\r\nDTSTART:19701025T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nRRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\nEND:STANDARD\r\n" +
+	"BEGIN:DAYLIGHT\r\nDTSTART:19700329T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\nEND:DAYLIGHT\r\n" +
+	"END:VTIMEZONE\r\n"
+
+func TestZoneResolver_Parse(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+
+	cal, err := parseCalendar(strings.NewReader("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + customZoneICS +
+		"BEGIN:VTIMEZONE\r\nTZID:Mozilla Zone\r\nX-LIC-LOCATION:Asia/Tokyo\r\nEND:VTIMEZONE\r\nEND:VCALENDAR\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zones := newZoneResolver(newYork)
+	zones.add(cal)
+
+	tests := []struct {
+		name       string
+		value      string
+		tzid       string
+		wantOffset int
+		wantAllDay bool
+	}{
+		{"UTC", "20260115T090000Z", "", 0, false},
+		{"IANA", "20260115T090000", "Europe/Berlin", 3600, false},
+		{"Windows", "20260715T090000", "W. Europe Standard Time", 7200, false},
+		{"MozillaPrefix", "20260115T090000", "/mozilla.org/20050126_1/Europe/Berlin", 3600, false},
+		{"XLicLocation", "20260115T090000", "Mozilla Zone", 9 * 3600, false},
+		{"VTimezoneWinter", "20260115T090000", "Custom Europe", 3600, false},
+		{"VTimezoneSummer", "20260715T090000", "Custom Europe", 7200, false},
+		{"UnknownIsFloating", "20260115T090000", "Nowhere/Special", -5 * 3600, false},
+		{"Floating", "20260115T090000", "", -5 * 3600, false},
+		{"DateIsFloating", "20260115", "Europe/Berlin", -5 * 3600, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, err := zones.parse(tt.value, tt.tzid)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if _, offset := got.Zone(); offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d (%v)", offset, tt.wantOffset, got)
+			}
+			if allDay != tt.wantAllDay {
+				t.Errorf("AllDay = %v, want %v", allDay, tt.wantAllDay)
+			}
+			wantHour := 9
+			if tt.wantAllDay {
+				wantHour = 0
+			}
+			if got.Hour() != wantHour && tt.tzid != "" {
+				t.Errorf("Wall clock changed: %v", got)
+			}
+		})
+	}
+}
+
+func TestExpandEvents_CustomZoneAcrossDST(t *testing.T) {
+	from := time.Date(2026, 3, 22, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 12, 0, 0, 0, 0, time.UTC)
+
+	// Mondays at 09:00 local: before and after the switch to summer time,
+	// with the third week excluded.
+	events := expandICS(t, customZoneICS+
+		"BEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Standup\r\nDTSTART;TZID=Custom Europe:20260105T090000\r\nRRULE:FREQ=WEEKLY\r\n"+
+		"EXDATE;TZID=Custom Europe:20260406T090000\r\nEND:VEVENT\r\n", from, to)
+
+	want := []time.Time{
+		time.Date(2026, 3, 23, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 30, 7, 0, 0, 0, time.UTC),
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i := range want {
+		if !events[i].Start.Equal(want[i]) {
+			t.Errorf("Event %d starts at %v, want %v", i, events[i].Start.UTC(), want[i])
+		}
+	}
+}
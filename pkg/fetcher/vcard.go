@@ -0,0 +1,191 @@
+package fetcher
+
+import (
+	"io"
+	"mime/quotedprintable"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/emersion/go-vcard"
+)
+
+// Kinds of yearly contact dates.
+const (
+	KindBirthday    = "birthday"
+	KindAnniversary = "anniversary"
+)
+
+// contactDate is a yearly date read from a vCard. Year is 0 when the card
+// leaves it out.
+type contactDate struct {
+	name  string
+	kind  string
+	year  int
+	month int
+	day   int
+}
+
+// parseVCards reads the birthdays and anniversaries of the vCards in r.
+// Cards without a name or a readable date are skipped.
+func parseVCards(r io.Reader) ([]contactDate, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []contactDate
+	dec := vcard.NewDecoder(strings.NewReader(normalizeVCard21(string(raw))))
+	for {
+		card, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The decoder resumes at the next line, so a broken card only
+			// loses itself.
+			continue
+		}
+		dates = append(dates, cardDates(card)...)
+	}
+	return dates, nil
+}
+
+// normalizeVCard21 rewrites what vCard 2.1 does differently from later
+// versions so the decoder can read it: quoted-printable values continued
+// with a soft line break are joined, and bare parameters such as
+// "QUOTED-PRINTABLE" or "HOME" get their ENCODING or TYPE name.
+func normalizeVCard21(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		head, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.Contains(head, ";") || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			out = append(out, line)
+			continue
+		}
+
+		parts := strings.Split(head, ";")
+		for j, p := range parts[1:] {
+			if strings.Contains(p, "=") {
+				continue
+			}
+			switch strings.ToUpper(p) {
+			case "QUOTED-PRINTABLE", "BASE64", "8BIT", "7BIT":
+				parts[j+1] = "ENCODING=" + p
+			default:
+				parts[j+1] = "TYPE=" + p
+			}
+		}
+		head = strings.Join(parts, ";")
+
+		if strings.Contains(strings.ToUpper(head), "QUOTED-PRINTABLE") {
+			for strings.HasSuffix(value, "=") && i+1 < len(lines) {
+				i++
+				value = strings.TrimSuffix(value, "=") + strings.TrimLeft(lines[i], " \t")
+			}
+		}
+		out = append(out, head+":"+value)
+	}
+	return strings.Join(out, "\r\n")
+}
+
+// cardDates returns the birthday and anniversary of a card.
+func cardDates(card vcard.Card) []contactDate {
+	name := ""
+	if fn := card.Preferred(vcard.FieldFormattedName); fn != nil {
+		name = fieldText(fn)
+	}
+	if name == "" {
+		// N is "Family;Given;Additional;Prefixes;Suffixes".
+		if n := card.Preferred(vcard.FieldName); n != nil {
+			fields := strings.Split(fieldText(n), ";")
+			if len(fields) > 1 {
+				fields[0], fields[1] = fields[1], fields[0]
+			}
+			name = strings.Join(fields, " ")
+		}
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return nil
+	}
+
+	var dates []contactDate
+	seen := make(map[string]bool)
+	for _, prop := range []struct{ field, kind string }{
+		{vcard.FieldBirthday, KindBirthday},
+		{vcard.FieldAnniversary, KindAnniversary},
+		{"X-ANNIVERSARY", KindAnniversary},
+	} {
+		for _, f := range card[prop.field] {
+			if seen[prop.kind] {
+				break
+			}
+			year, month, day, ok := parseVCardDate(fieldText(f))
+			if !ok {
+				continue
+			}
+			// Apple stores dates without a year with a placeholder year.
+			if omit := f.Params.Get("X-APPLE-OMIT-YEAR"); omit != "" && omit == strconv.Itoa(year) {
+				year = 0
+			}
+			seen[prop.kind] = true
+			dates = append(dates, contactDate{name: name, kind: prop.kind, year: year, month: month, day: day})
+		}
+	}
+	return dates
+}
+
+// fieldText returns the value of a field as UTF-8, decoding the
+// quoted-printable values and Latin-1 charset of vCard 2.1.
+func fieldText(f *vcard.Field) string {
+	value := f.Value
+	if strings.EqualFold(f.Params.Get("ENCODING"), "QUOTED-PRINTABLE") {
+		// A soft line break the server did not join leaves a trailing "=".
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(strings.TrimSuffix(value, "="))))
+		if err == nil {
+			value = string(decoded)
+		}
+	}
+	if strings.EqualFold(f.Params.Get("CHARSET"), "ISO-8859-1") && !utf8.ValidString(value) {
+		runes := make([]rune, len(value))
+		for i := 0; i < len(value); i++ {
+			runes[i] = rune(value[i])
+		}
+		value = string(runes)
+	}
+	return value
+}
+
+// parseVCardDate reads a date in basic or extended format, with or without a
+// year ("19850412", "1985-04-12", "--0412", "--04-12"). A time part is
+// ignored.
+func parseVCardDate(s string) (year, month, day int, ok bool) {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "T")
+	noYear := strings.HasPrefix(s, "--")
+	s = strings.ReplaceAll(strings.TrimPrefix(s, "--"), "-", "")
+
+	digits := s
+	if !noYear {
+		if len(s) != 8 {
+			return 0, 0, 0, false
+		}
+		digits = s[4:]
+		y, err := strconv.Atoi(s[:4])
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		year = y
+	}
+	if len(digits) != 4 {
+		return 0, 0, 0, false
+	}
+	m, err1 := strconv.Atoi(digits[:2])
+	d, err2 := strconv.Atoi(digits[2:])
+	if err1 != nil || err2 != nil || m < 1 || m > 12 || d < 1 || d > 31 {
+		return 0, 0, 0, false
+	}
+	return year, m, d, true
+}
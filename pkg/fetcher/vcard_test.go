@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVCards(t *testing.T) {
+	vcf := "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Jane Doe\r\nBDAY:1985-04-12\r\nANNIVERSARY:20100619\r\nEND:VCARD\r\n" +
+		// Folded name, birthday without a year.
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Max\r\n  Mustermann\r\nBDAY:--0229\r\nEND:VCARD\r\n" +
+		// Name from N, Apple's placeholder year and a grouped property.
+		"BEGIN:VCARD\r\nVERSION:3.0\r\nN:Smith;John\\, Jr.;;;\r\nitem1.BDAY;X-APPLE-OMIT-YEAR=1604:1604-12-24\r\nEND:VCARD\r\n" +
+		// Date with a time part and a text date that cannot be read.
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Ann\r\nBDAY:19900102T000000Z\r\nX-ANNIVERSARY;VALUE=text:sometime in June\r\nEND:VCARD\r\n" +
+		// vCard 2.1: quoted-printable with a soft line break, and a bare
+		// encoding parameter with a Latin-1 charset.
+		"BEGIN:VCARD\r\nVERSION:2.1\r\nFN;CHARSET=UTF-8;ENCODING=QUOTED-PRINTABLE:J=C3=B6rg M=C3=BC=\r\nller\r\nBDAY:19700301\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:2.1\r\nN;CHARSET=ISO-8859-1;QUOTED-PRINTABLE:G=F6tz;Ren=E9\r\nBDAY:1971-08-09\r\nEND:VCARD\r\n" +
+		// No dates at all.
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Nobody\r\nEND:VCARD\r\n"
+
+	got, err := parseVCards(strings.NewReader(vcf))
+	if err != nil {
+		t.Fatalf("parseVCards failed: %v", err)
+	}
+	want := []contactDate{
+		{name: "Jane Doe", kind: KindBirthday, year: 1985, month: 4, day: 12},
+		{name: "Jane Doe", kind: KindAnniversary, year: 2010, month: 6, day: 19},
+		{name: "Max Mustermann", kind: KindBirthday, month: 2, day: 29},
+		{name: "John, Jr. Smith", kind: KindBirthday, month: 12, day: 24},
+		{name: "Ann", kind: KindBirthday, year: 1990, month: 1, day: 2},
+		{name: "Jörg Müller", kind: KindBirthday, year: 1970, month: 3, day: 1},
+		{name: "René Götz", kind: KindBirthday, year: 1971, month: 8, day: 9},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d dates, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Date %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseVCardDate(t *testing.T) {
+	tests := []struct {
+		in               string
+		year, month, day int
+		ok               bool
+	}{
+		{"19850412", 1985, 4, 12, true},
+		{"1985-04-12", 1985, 4, 12, true},
+		{"--0412", 0, 4, 12, true},
+		{"--04-12", 0, 4, 12, true},
+		{"1985-13-01", 0, 0, 0, false},
+		{"1985", 0, 0, 0, false},
+		{"", 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		year, month, day, ok := parseVCardDate(tt.in)
+		if ok != tt.ok || (ok && (year != tt.year || month != tt.month || day != tt.day)) {
+			t.Errorf("parseVCardDate(%q) = %d, %d, %d, %v", tt.in, year, month, day, ok)
+		}
+	}
+}
@@ -3,53 +3,40 @@ package textutil
 import (
 	"regexp"
 	"strings"
-)
+	"unicode"
 
-var (
-	nonAlphanumericRegex = regexp.MustCompile(`[^a-z0-9\s]`)
-	whitespaceRegex      = regexp.MustCompile(`\s+`)
-	leadingCleanupRegex  = regexp.MustCompile(`^[\s,.\-–—:]+`)
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
 )
 
-// NormalizeText prepares text for comparison by lowercasing and removing special chars.
-// Logic ported from app.js:normalizeText
+var leadingCleanupRegex = regexp.MustCompile(`^[\s,.\-–—:。、，：]+`)
+
+// NormalizeText prepares text for comparison: case and diacritics are folded,
+// letters and numbers of any script are kept and everything else is dropped.
 func NormalizeText(text string) string {
-	s := strings.ToLower(text)
-	s = nonAlphanumericRegex.ReplaceAllString(s, "")
-	s = whitespaceRegex.ReplaceAllString(s, " ")
-	return strings.TrimSpace(s)
+	return strings.Join(strings.Fields(fold(text)), " ")
 }
 
-// StripHTML removes HTML tags from a string.
-// Logic ported from app.js:stripHtml
-func StripHTML(html string) string {
-	if html == "" {
-		return ""
-	}
-
-	// Replace block tags with space to prevent words merging
-	// Simple regex approach as per original JS implementation
-	// Note: A proper HTML parser would be better but this maintains behavior parity
-	// for the "nonsense" removal refactor.
-	blockTags := regexp.MustCompile(`(?i)<\/?(a|li|p|div|br|h[1-6])[^>]*>`)
-	s := blockTags.ReplaceAllString(html, " ")
-
-	// Remove all other tags
-	allTags := regexp.MustCompile(`<[^>]+>`)
-	s = allTags.ReplaceAllString(s, "")
-
-	// Replace nbsp
-	s = strings.ReplaceAll(s, "&nbsp;", " ")
-
-	// Normalize whitespace
-	s = whitespaceRegex.ReplaceAllString(s, " ")
-	return strings.TrimSpace(s)
+// fold applies case folding and NFKD decomposition, then keeps only letters,
+// numbers and whitespace. Combining marks left by the decomposition are
+// dropped, so "Café" and "cafe" fold to the same string.
+func fold(text string) string {
+	s := norm.NFKD.String(cases.Fold().String(text))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, s)
 }
 
-// CleanSummary removes the title from the beginning of the summary if present,
-// and performs general cleanup.
+// CleanSummary converts the summary to plain text and removes the title from
+// its beginning if present.
 func CleanSummary(summary, title string) string {
-	summary = StripHTML(summary)
+	summary = HTMLToText(summary)
 	if summary == "" {
 		return ""
 	}
@@ -71,36 +58,24 @@ func CleanSummary(summary, title string) string {
 	return strings.TrimSpace(leadingCleanupRegex.ReplaceAllString(summary, ""))
 }
 
-// findMatchLength finds the length of the prefix in summary that matches title (fuzzily).
-// Logic ported from app.js:findMatchLength
+// findMatchLength returns the byte length of the prefix of summary that
+// matches title, ignoring case, diacritics, punctuation and spacing.
 func findMatchLength(summary, title string) int {
-	normTitle := NormalizeText(title)
-	if normTitle == "" {
+	want := []rune(strings.ReplaceAll(NormalizeText(title), " ", ""))
+	if len(want) == 0 {
 		return 0
 	}
 
-	normTitleChars := strings.ReplaceAll(normTitle, " ", "")
-
-	summaryRunes := []rune(summary)
-	matchedNormChars := 0
-	summaryIndex := 0
-
-	for summaryIndex < len(summaryRunes) && matchedNormChars < len(normTitleChars) {
-		char := summaryRunes[summaryIndex]
-		lowerChar := strings.ToLower(string(char))
-
-		isAlphanumeric := false
-		if (lowerChar >= "a" && lowerChar <= "z") || (lowerChar >= "0" && lowerChar <= "9") {
-			isAlphanumeric = true
+	matched := 0
+	for i, r := range summary {
+		if matched >= len(want) {
+			return i
 		}
-
-		if isAlphanumeric {
-			if lowerChar == string(normTitleChars[matchedNormChars]) {
-				matchedNormChars++
+		for _, fr := range fold(string(r)) {
+			if matched < len(want) && fr == want[matched] {
+				matched++
 			}
 		}
-		summaryIndex++
 	}
-
-	return summaryIndex
+	return len(summary)
 }
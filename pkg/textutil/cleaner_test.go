@@ -41,6 +41,30 @@ func TestCleanSummary(t *testing.T) {
 			title:   "Just the title",
 			want:    "",
 		},
+		{
+			name:    "Cyrillic Overlap",
+			summary: "Новости дня: Путин встретился с Си. Подробности позже.",
+			title:   "Новости дня — Путин встретился с Си",
+			want:    "Подробности позже.",
+		},
+		{
+			name:    "Greek Diacritics",
+			summary: "ΣΕΙΣΜΌΣ ΣΤΗΝ ΚΡΉΤΗ. Δεν υπάρχουν ζημιές.",
+			title:   "Σεισμός στην Κρήτη",
+			want:    "Δεν υπάρχουν ζημιές.",
+		},
+		{
+			name:    "CJK Overlap",
+			summary: "東京で大雨警報。交通機関に影響。",
+			title:   "東京で大雨警報",
+			want:    "交通機関に影響。",
+		},
+		{
+			name:    "Latin Diacritics",
+			summary: "Cafe de Paris reopens - after two years.",
+			title:   "Café de París reopens",
+			want:    "after two years.",
+		},
 	}
 
 	for _, tt := range tests {
@@ -51,3 +75,26 @@ func TestCleanSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Hello, World!", "hello world"},
+		{"  Multiple   spaces\n", "multiple spaces"},
+		{"Crème Brûlée", "creme brulee"},
+		{"Straße", "strasse"},
+		{"ＦＵＬＬ－ＷＩＤＴＨ", "fullwidth"},
+		{"Привет, МИР", "привет мир"},
+		{"Ἀθῆναι", "αθηναι"},
+		{"北京 2024", "北京 2024"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeText(tt.in); got != tt.want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
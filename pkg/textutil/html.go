@@ -0,0 +1,181 @@
+package textutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultSummaryLength is the number of characters summaries are truncated to
+// when no length is configured.
+const DefaultSummaryLength = 280
+
+// Tags that start a new paragraph or a new line in the text output.
+var (
+	paragraphTags = map[string]bool{
+		"p": true, "div": true, "section": true, "article": true, "header": true,
+		"footer": true, "blockquote": true, "pre": true, "ul": true, "ol": true,
+		"table": true, "hr": true, "h1": true, "h2": true, "h3": true, "h4": true,
+		"h5": true, "h6": true,
+	}
+	lineTags = map[string]bool{
+		"br": true, "li": true, "tr": true, "dt": true, "dd": true,
+	}
+	skippedTags = map[string]bool{
+		"script": true, "style": true, "head": true, "title": true, "noscript": true,
+	}
+)
+
+var cdataMarkers = strings.NewReplacer("<![CDATA[", "", "]]>", "")
+
+// HTMLToText converts an HTML fragment to plain text. Entities are decoded,
+// script and style content is dropped, whitespace is collapsed and block
+// elements become line or paragraph breaks. Unbalanced tags are tolerated.
+func HTMLToText(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	var w textWriter
+	z := html.NewTokenizer(strings.NewReader(cdataMarkers.Replace(s)))
+	skip := ""
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return w.String()
+		case html.TextToken:
+			if skip == "" {
+				w.text(string(z.Text()))
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skip != "" {
+				continue
+			}
+			if skippedTags[tag] {
+				skip = tag
+				continue
+			}
+			w.tag(tag)
+		case html.SelfClosingTagToken:
+			// A self-closing <script/> has no content and no end tag, so it
+			// must neither start skipping nor leave the tokenizer reading
+			// what follows as raw script text.
+			z.NextIsNotRawText()
+			name, _ := z.TagName()
+			if tag := string(name); skip == "" && !skippedTags[tag] {
+				w.tag(tag)
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skip != "" {
+				if tag == skip {
+					skip = ""
+				}
+				continue
+			}
+			w.tag(tag)
+		}
+	}
+}
+
+// FirstImageSrc returns the src of the first <img> in an HTML fragment.
+func FirstImageSrc(s string) string {
+	if !strings.Contains(s, "<") {
+		return ""
+	}
+
+	z := html.NewTokenizer(strings.NewReader(cdataMarkers.Replace(s)))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "img" {
+				continue
+			}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				if string(key) == "src" && len(val) > 0 {
+					return string(val)
+				}
+			}
+		}
+	}
+}
+
+// textWriter joins text fragments, deferring spaces and breaks until the next
+// word so that output never starts or ends with whitespace.
+type textWriter struct {
+	b      strings.Builder
+	space  bool
+	breaks int
+}
+
+func (w *textWriter) tag(name string) {
+	switch {
+	case paragraphTags[name]:
+		w.breaks = 2
+	case lineTags[name] && w.breaks < 1:
+		w.breaks = 1
+	}
+}
+
+func (w *textWriter) text(s string) {
+	if s == "" {
+		return
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	if unicode.IsSpace(r) {
+		w.space = true
+	}
+
+	for _, word := range strings.Fields(s) {
+		if w.b.Len() > 0 {
+			switch {
+			case w.breaks > 0:
+				w.b.WriteString(strings.Repeat("\n", w.breaks))
+			case w.space:
+				w.b.WriteByte(' ')
+			}
+		}
+		w.b.WriteString(word)
+		w.breaks = 0
+		w.space = true
+	}
+
+	r, _ = utf8.DecodeLastRuneInString(s)
+	w.space = unicode.IsSpace(r)
+}
+
+func (w *textWriter) String() string {
+	return w.b.String()
+}
+
+// Truncate shortens text to at most max characters, the ellipsis marking
+// the cut included. The cut happens at the last word boundary. max <= 0
+// disables truncation.
+func Truncate(s string, max int) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+
+	cut := runes[:max-1]
+	// Only back up to a word boundary if that keeps most of the text; a single
+	// long word (or CJK text without spaces) is cut mid-word instead.
+	for i := len(cut) - 1; i > max/2; i-- {
+		if unicode.IsSpace(cut[i]) {
+			cut = cut[:i]
+			break
+		}
+	}
+
+	return strings.TrimRight(string(cut), " \t\n,.;:-–—") + "…"
+}
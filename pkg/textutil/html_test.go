@@ -0,0 +1,87 @@
+package textutil
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Plain", "Just text", "Just text"},
+		{"NamedEntities", "Fish &amp; Chips &mdash; &euro;5", "Fish & Chips — €5"},
+		{"NumericEntities", "It&#8217;s &#x201C;quoted&#x201D;", "It’s “quoted”"},
+		{"Nbsp", "a&nbsp;b", "a b"},
+		{"InlineTags", "<p><b>Bold</b> and <i>italic</i></p>", "Bold and italic"},
+		{"NoSpaceBetweenInline", "un<b>believ</b>able", "unbelievable"},
+		{"Paragraphs", "<p>First</p><p>Second</p>", "First\n\nSecond"},
+		{"LineBreaks", "One<br>Two<br/>Three", "One\nTwo\nThree"},
+		{"List", "<ul><li>A</li><li>B</li></ul>", "A\nB"},
+		{"ScriptAndStyle", "Keep<script>alert('x')</script> this<style>p{color:red}</style>", "Keep this"},
+		{"UnbalancedTags", "<div><p>Open <b>never closed<p>Next", "Open never closed\n\nNext"},
+		{"StrayLessThan", "5 < 6 and 7 > 3", "5 < 6 and 7 > 3"},
+		{"SelfClosingScript", "<p>a</p><script src=\"x\"/><p>visible text</p>", "a\n\nvisible text"},
+		{"SelfClosingStyle", "<style/>hello world", "hello world"},
+		{"CDATA", "<![CDATA[<p>Inside</p>]]>", "Inside"},
+		{"Whitespace", "  lots \n\t of   space  ", "lots of space"},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.in); got != tt.want {
+				t.Errorf("HTMLToText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		max  int
+		want string
+	}{
+		{"Short", "Short text", 20, "Short text"},
+		{"Disabled", "Short text", 0, "Short text"},
+		{"WordBoundary", "The quick brown fox jumps", 17, "The quick brown…"},
+		{"TrailingPunctuation", "Hello, world and more", 8, "Hello…"},
+		{"LongWord", "Supercalifragilistic", 10, "Supercali…"},
+		{"Runes", "Привет мир и все", 12, "Привет мир…"},
+		{"Limit", "abcdefghij klmnop", 10, "abcdefghi…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.in, tt.max)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+			}
+			if tt.max > 0 && utf8.RuneCountInString(got) > tt.max {
+				t.Errorf("Truncate(%q, %d) has %d characters", tt.in, tt.max, utf8.RuneCountInString(got))
+			}
+		})
+	}
+}
+
+func TestFirstImageSrc(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`<p>Text <img alt="x" src="http://example.com/a.jpg"> <img src="b.jpg"></p>`, "http://example.com/a.jpg"},
+		{`<img src='/relative.png'/>`, "/relative.png"},
+		{`<img alt="no src">`, ""},
+		{"No markup", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FirstImageSrc(tt.in); got != tt.want {
+			t.Errorf("FirstImageSrc(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package textutil
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/goodsign/monday"
+)
+
+// phrases are the words of one language that dates and times are described
+// relative to now with.
+type phrases struct {
+	yesterday, today, tomorrow string
+	countdown                  CountdownWords
+}
+
+// languages holds the phrases by ISO 639-1 language code. Locales of other
+// languages use English.
+var languages = map[string]phrases{
+	"en": {"Yesterday", "Today", "Tomorrow", CountdownWords{"now", "in %s", "h", "min"}},
+	"cs": {"Včera", "Dnes", "Zítra", CountdownWords{"nyní", "za %s", "h", "min"}},
+	"da": {"I går", "I dag", "I morgen", CountdownWords{"nu", "om %s", "t", "min"}},
+	"de": {"Gestern", "Heute", "Morgen", CountdownWords{"jetzt", "in %s", "Std.", "Min."}},
+	"es": {"Ayer", "Hoy", "Mañana", CountdownWords{"ahora", "en %s", "h", "min"}},
+	"fi": {"Eilen", "Tänään", "Huomenna", CountdownWords{"nyt", "%s kuluttua", "h", "min"}},
+	"fr": {"Hier", "Aujourd'hui", "Demain", CountdownWords{"maintenant", "dans %s", "h", "min"}},
+	"it": {"Ieri", "Oggi", "Domani", CountdownWords{"ora", "tra %s", "h", "min"}},
+	"nb": {"I går", "I dag", "I morgen", CountdownWords{"nå", "om %s", "t", "min"}},
+	"nl": {"Gisteren", "Vandaag", "Morgen", CountdownWords{"nu", "over %s", "u", "min"}},
+	"pl": {"Wczoraj", "Dzisiaj", "Jutro", CountdownWords{"teraz", "za %s", "godz.", "min"}},
+	"pt": {"Ontem", "Hoje", "Amanhã", CountdownWords{"agora", "em %s", "h", "min"}},
+	"ru": {"Вчера", "Сегодня", "Завтра", CountdownWords{"сейчас", "через %s", "ч", "мин"}},
+	"sv": {"I går", "I dag", "I morgon", CountdownWords{"nu", "om %s", "tim", "min"}},
+}
+
+// phrasesFor returns the phrases of a locale such as "de_DE", "de-DE" or
+// "de".
+func phrasesFor(locale string) phrases {
+	lang, _, _ := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	lang = strings.ToLower(lang)
+	if lang == "no" || lang == "nn" {
+		lang = "nb"
+	}
+	if p, ok := languages[lang]; ok {
+		return p
+	}
+	return languages["en"]
+}
+
+// DateLocale returns the locale dates are formatted in with monday, English
+// by default. BCP 47 tags such as "de-DE" are accepted as well as "de_DE".
+func DateLocale(locale string) monday.Locale {
+	if locale == "" {
+		return monday.LocaleEnUS
+	}
+	return monday.Locale(strings.ReplaceAll(locale, "-", "_"))
+}
+
+// RelativeDay names the day days after today in the language of locale:
+// "Yesterday", "Today" or "Tomorrow". Other days have no name.
+func RelativeDay(days int, locale string) (string, bool) {
+	p := phrasesFor(locale)
+	switch days {
+	case -1:
+		return p.yesterday, true
+	case 0:
+		return p.today, true
+	case 1:
+		return p.tomorrow, true
+	}
+	return "", false
+}
+
+// CountdownWords are the words the time left until an event is written
+// with. They are handed to the dashboard script as well, so both displays
+// count down alike.
+type CountdownWords struct {
+	Now string `json:"now"`
+	// In wraps the time left, e.g. "in %s".
+	In     string `json:"in"`
+	Hour   string `json:"hour"`
+	Minute string `json:"minute"`
+}
+
+// Countdown returns the countdown words of a locale.
+func Countdown(locale string) CountdownWords {
+	return phrasesFor(locale).countdown
+}
+
+// Format describes the time left, e.g. "in 12 min" or "in 1 h 30 min".
+func (w CountdownWords) Format(d time.Duration) string {
+	minutes := int(math.Ceil(d.Minutes()))
+	switch {
+	case minutes <= 0:
+		return w.Now
+	case minutes < 60:
+		return fmt.Sprintf(w.In, fmt.Sprintf("%d %s", minutes, w.Minute))
+	case minutes%60 == 0:
+		return fmt.Sprintf(w.In, fmt.Sprintf("%d %s", minutes/60, w.Hour))
+	default:
+		return fmt.Sprintf(w.In, fmt.Sprintf("%d %s %d %s", minutes/60, w.Hour, minutes%60, w.Minute))
+	}
+}
@@ -0,0 +1,65 @@
+package textutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeDay(t *testing.T) {
+	tests := []struct {
+		name   string
+		days   int
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"Default", 0, "", "Today", true},
+		{"English", 1, "en_US", "Tomorrow", true},
+		{"German", 0, "de_DE", "Heute", true},
+		{"BCP47", -1, "fr-CA", "Hier", true},
+		{"LanguageOnly", 1, "es", "Mañana", true},
+		{"Norwegian", 0, "no", "I dag", true},
+		{"Unknown", 1, "xx_XX", "Tomorrow", true},
+		{"NoName", 2, "de_DE", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RelativeDay(tt.days, tt.locale)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("RelativeDay(%d, %q) = %q, %v, want %q, %v", tt.days, tt.locale, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	tests := []struct {
+		in     time.Duration
+		locale string
+		want   string
+	}{
+		{-time.Minute, "", "now"},
+		{30 * time.Second, "", "in 1 min"},
+		{12 * time.Minute, "en-US", "in 12 min"},
+		{2 * time.Hour, "", "in 2 h"},
+		{90 * time.Minute, "", "in 1 h 30 min"},
+		{90 * time.Minute, "de_DE", "in 1 Std. 30 Min."},
+		{5 * time.Minute, "fi", "5 min kuluttua"},
+		{0, "fr_FR", "maintenant"},
+	}
+	for _, tt := range tests {
+		if got := Countdown(tt.locale).Format(tt.in); got != tt.want {
+			t.Errorf("Countdown(%q).Format(%v) = %q, want %q", tt.locale, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDateLocale(t *testing.T) {
+	tests := map[string]string{"": "en_US", "de_DE": "de_DE", "de-DE": "de_DE", "pt-BR": "pt_BR"}
+	for in, want := range tests {
+		if got := DateLocale(in); string(got) != want {
+			t.Errorf("DateLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}